@@ -27,37 +27,54 @@ type Authentication struct {
 	ExpiresAt int64 `json:"expires_at,omitempty"`
 }
 
-// Authenticate swaps username/password for an authentication token
+// Authenticate swaps username/password for an authentication token. It is
+// safe to call concurrently with requests made by the client.
 //
 // Codeship API docs: https://apidocs.codeship.com/v2/authentication/authentication-endpoint
 func (c *Client) Authenticate(ctx context.Context) (Response, error) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	_, resp, err := c.authenticate(ctx)
+	return resp, err
+}
+
+// authenticate requests a new token and stores it, returning the new
+// Authentication. The caller must hold authMu.
+func (c *Client) authenticate(ctx context.Context) (auth Authentication, resp Response, err error) {
 	path := "/auth"
+
+	ctx, span := c.startSpan(ctx, "Authenticate", "POST", path)
+	defer func() {
+		endSpan(span, resp, err)
+	}()
+
 	req, _ := http.NewRequest("POST", c.baseURL+path, nil)
 	c.authenticator.SetAuth(req)
 	req.Header.Set("Content-Type", "application/json")
 
-	c.authentication = Authentication{}
+	c.setAuthentication(Authentication{})
 
-	body, resp, err := c.do(req.WithContext(ctx))
+	body, resp, err := c.do("Authenticate", req.WithContext(ctx))
 	if err != nil {
-		return resp, err
+		return Authentication{}, resp, err
 	}
 
-	var auth = &struct {
+	var result = &struct {
 		Authentication
 		Error string `json:"error,omitempty"`
 	}{}
 
-	if err = json.Unmarshal(body, auth); err != nil {
-		return resp, errors.Wrap(err, "unable to unmarshal JSON")
+	if err = json.Unmarshal(body, result); err != nil {
+		return Authentication{}, resp, errors.Wrap(err, "unable to unmarshal JSON")
 	}
 
-	if auth.Error != "" {
-		return resp, toError(auth.Error)
+	if result.Error != "" {
+		return Authentication{}, resp, toError(result.Error)
 	}
 
-	c.authentication = auth.Authentication
-	return resp, err
+	c.setAuthentication(result.Authentication)
+	return result.Authentication, resp, nil
 }
 
 func toError(msg string) error {
@@ -0,0 +1,305 @@
+// Package backup snapshots the configuration of every project in an
+// organization into one file per project and restores projects from those
+// snapshots. Snapshots are written deterministically, so committing them to a
+// repository after each backup gives a history of configuration changes.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/internal/fileutil"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Redacted replaces secrets in a redacted snapshot
+const Redacted = "[REDACTED]"
+
+// Format is the encoding of snapshot files
+type Format int
+
+const (
+	// FormatJSON writes snapshots as indented JSON
+	FormatJSON Format = iota
+	// FormatYAML writes snapshots as YAML with sorted keys
+	FormatYAML
+)
+
+var _formatValueToName = map[Format]string{
+	FormatJSON: "json",
+	FormatYAML: "yaml",
+}
+
+var _formatValueToExt = map[Format]string{
+	FormatJSON: ".json",
+	FormatYAML: ".yml",
+}
+
+func (f Format) String() string {
+	return _formatValueToName[f]
+}
+
+// Ext returns the file extension of snapshots in this format
+func (f Format) Ext() string {
+	return _formatValueToExt[f]
+}
+
+// Lister is the subset of *codeship.Organization used to take snapshots
+type Lister interface {
+	ListAllProjects(ctx context.Context) ([]codeship.Project, error)
+}
+
+var _ Lister = &codeship.Organization{}
+
+// Options configures a backup
+type Options struct {
+	Format Format
+	// Redact replaces the AES and SSH keys, environment variable values,
+	// notification keys and URLs and deployment pipeline configuration with
+	// Redacted. Secrets can not be restored from a redacted snapshot.
+	Redact bool
+}
+
+// Result lists the files changed by Backup
+type Result struct {
+	Written []string
+	// Removed are the snapshots of projects that no longer exist
+	Removed []string
+}
+
+// Snapshot lists every project of the organization, sorted by UUID, with
+// their lists sorted so that unchanged projects produce identical snapshots
+func Snapshot(ctx context.Context, client Lister, opts Options) ([]codeship.Project, error) {
+	projects, err := client.ListAllProjects(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to snapshot projects")
+	}
+
+	for i := range projects {
+		projects[i] = normalize(projects[i])
+		if opts.Redact {
+			projects[i] = redact(projects[i])
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].UUID < projects[j].UUID
+	})
+
+	return projects, nil
+}
+
+// Backup writes a snapshot of every project to dir, named by project UUID, and
+// removes the snapshots of projects that no longer exist. Only files named
+// <uuid>.json, <uuid>.yml or <uuid>.yaml are treated as snapshots, so dir may
+// contain other files, e.g. the root of a repository.
+func Backup(ctx context.Context, client Lister, dir string, opts Options) (Result, error) {
+	projects, err := Snapshot(ctx, client, opts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Result{}, errors.Wrap(err, "unable to create backup directory")
+	}
+
+	var result Result
+	written := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		b, err := Marshal(p, opts.Format)
+		if err != nil {
+			return result, err
+		}
+
+		path := filepath.Join(dir, p.UUID+opts.Format.Ext())
+		if err := fileutil.WriteFileAtomic(path, b, 0600); err != nil {
+			return result, errors.Wrapf(err, "unable to write snapshot of project %s", p.UUID)
+		}
+		written[path] = true
+		result.Written = append(result.Written, path)
+	}
+
+	files, err := snapshotFiles(dir)
+	if err != nil {
+		return result, err
+	}
+	for _, path := range files {
+		if written[path] {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return result, errors.Wrap(err, "unable to remove snapshot")
+		}
+		result.Removed = append(result.Removed, path)
+	}
+
+	return result, nil
+}
+
+// Load reads every snapshot in dir, sorted by file name, ignoring files that
+// are not named after a project UUID
+func Load(dir string) ([]codeship.Project, error) {
+	files, err := snapshotFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]codeship.Project, 0, len(files))
+	for _, path := range files {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read snapshot")
+		}
+
+		p, err := Unmarshal(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to load %s", path)
+		}
+		projects = append(projects, p)
+	}
+
+	return projects, nil
+}
+
+// Marshal encodes a project snapshot
+func Marshal(p codeship.Project, format Format) ([]byte, error) {
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal snapshot")
+	}
+
+	if format != FormatYAML {
+		return append(b, '\n'), nil
+	}
+
+	// Convert through a generic value so that the json tags of the codeship
+	// types are used as YAML keys
+	var raw interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, errors.Wrap(err, "unable to marshal snapshot")
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(raw); err != nil {
+		return nil, errors.Wrap(err, "unable to marshal snapshot")
+	}
+	if err := enc.Close(); err != nil {
+		return nil, errors.Wrap(err, "unable to marshal snapshot")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes a JSON or YAML project snapshot
+func Unmarshal(data []byte) (codeship.Project, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return codeship.Project{}, errors.Wrap(err, "unable to parse snapshot")
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return codeship.Project{}, errors.Wrap(err, "unable to parse snapshot")
+	}
+
+	var p codeship.Project
+	if err := json.Unmarshal(b, &p); err != nil {
+		return codeship.Project{}, errors.Wrap(err, "unable to parse snapshot")
+	}
+
+	if p.UUID == "" && p.RepositoryURL == "" {
+		return codeship.Project{}, errors.New("invalid snapshot: uuid or repository_url is required")
+	}
+
+	return p, nil
+}
+
+// snapshotName matches the file names of snapshots written by Backup
+var snapshotName = regexp.MustCompile(`(?i)^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\.(json|ya?ml)$`)
+
+// snapshotFiles returns the snapshots in dir, sorted by file name
+func snapshotFiles(dir string) ([]string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read backup directory")
+	}
+
+	var files []string
+	for _, info := range infos {
+		if info.Mode().IsRegular() && snapshotName.MatchString(info.Name()) {
+			files = append(files, filepath.Join(dir, info.Name()))
+		}
+	}
+	return files, nil
+}
+
+// normalize sorts the lists of a project whose order is not significant
+func normalize(p codeship.Project) codeship.Project {
+	p.TeamIDs = append([]int(nil), p.TeamIDs...)
+	sort.Ints(p.TeamIDs)
+
+	p.EnvironmentVariables = append([]codeship.EnvironmentVariable(nil), p.EnvironmentVariables...)
+	sort.SliceStable(p.EnvironmentVariables, func(i, j int) bool {
+		return p.EnvironmentVariables[i].Name < p.EnvironmentVariables[j].Name
+	})
+
+	// Rules have no natural key, so they are ordered by their encoding
+	p.NotificationRules = append([]codeship.NotificationRule(nil), p.NotificationRules...)
+	sort.SliceStable(p.NotificationRules, func(i, j int) bool {
+		a, _ := json.Marshal(p.NotificationRules[i])
+		b, _ := json.Marshal(p.NotificationRules[j])
+		return string(a) < string(b)
+	})
+
+	p.DeploymentPipelines = append([]codeship.DeploymentPipeline(nil), p.DeploymentPipelines...)
+	sort.SliceStable(p.DeploymentPipelines, func(i, j int) bool {
+		return p.DeploymentPipelines[i].Position < p.DeploymentPipelines[j].Position
+	})
+
+	return p
+}
+
+func redact(p codeship.Project) codeship.Project {
+	if p.AesKey != "" {
+		p.AesKey = Redacted
+	}
+	if p.SSHKey != "" {
+		p.SSHKey = Redacted
+	}
+
+	for i := range p.EnvironmentVariables {
+		p.EnvironmentVariables[i].Value = Redacted
+	}
+
+	for i := range p.NotificationRules {
+		options := &p.NotificationRules[i].Options
+		if options.Key != "" {
+			options.Key = Redacted
+		}
+		if options.URL != "" {
+			options.URL = Redacted
+		}
+	}
+
+	for i, d := range p.DeploymentPipelines {
+		if d.Config == nil {
+			continue
+		}
+		config := make(map[string]interface{}, len(d.Config))
+		for k := range d.Config {
+			config[k] = Redacted
+		}
+		p.DeploymentPipelines[i].Config = config
+	}
+
+	return p
+}
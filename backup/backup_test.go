@@ -0,0 +1,213 @@
+package backup_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/backup"
+	"github.com/codeship/codeship-go/internal/projecttest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func projects() []codeship.Project {
+	return []codeship.Project{
+		{
+			AuthenticationUser: "Test User",
+			CreatedAt:          time.Date(2017, 9, 8, 19, 19, 9, 0, time.UTC),
+			ID:                 2,
+			Name:               "org/other-project",
+			RepositoryProvider: "github",
+			RepositoryURL:      "https://github.com/org/other-project",
+			Type:               codeship.ProjectTypePro,
+			UpdatedAt:          time.Date(2017, 9, 13, 17, 13, 36, 0, time.UTC),
+			UUID:               "c38f3280-2d13-0134-d0b4-1e1b0ba06dd1",
+		},
+		{
+			AesKey:             "aeskey",
+			AuthenticationUser: "Test User",
+			CreatedAt:          time.Date(2017, 9, 8, 20, 19, 55, 0, time.UTC),
+			DeploymentPipelines: []codeship.DeploymentPipeline{
+				{ID: 5, Branch: codeship.DeploymentBranch{BranchName: "release/", MatchMode: "prefix"}, Config: map[string]interface{}{"api_key": "secret"}, Position: 2},
+				{ID: 4, Branch: codeship.DeploymentBranch{BranchName: "master", MatchMode: "exact"}, Position: 1},
+			},
+			EnvironmentVariables: []codeship.EnvironmentVariable{
+				{Name: "TOKEN", Value: "secret"},
+				{Name: "DATABASE_URL", Value: "postgres://db/test"},
+			},
+			ID:   1,
+			Name: "org/test-project",
+			NotificationRules: []codeship.NotificationRule{
+				codeship.SlackNotification("https://hooks.slack.com/services/T000/B000/XXX", codeship.NotifyOnFailed),
+				codeship.EmailNotification(codeship.NotificationTargetAll, codeship.NotifyOnFailed, codeship.NotifyOnRecovered),
+			},
+			OrganizationUUID:   "28123f10-e33d-5533-b53f-111ef8d7b14f",
+			RepositoryProvider: "github",
+			RepositoryURL:      "https://github.com/org/test-project",
+			SetupCommands:      []string{"./setup", "./seed"},
+			SSHKey:             "ssh-rsa key",
+			TeamIDs:            []int{61593, 1007},
+			TestPipelines: []codeship.TestPipeline{
+				{ID: 1, Name: "tests", Commands: []string{"make test"}},
+			},
+			Type:      codeship.ProjectTypeBasic,
+			UpdatedAt: time.Date(2017, 9, 13, 17, 13, 36, 0, time.UTC),
+			UUID:      "0059df30-7701-0135-8810-6e5f001a2e3c",
+		},
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	snapshot, err := backup.Snapshot(context.Background(), &projecttest.Client{Projects: projects()}, backup.Options{})
+	require.NoError(t, err)
+	require.Len(t, snapshot, 2)
+
+	p := snapshot[0]
+	assert.Equal(t, "0059df30-7701-0135-8810-6e5f001a2e3c", p.UUID, "sorted by uuid")
+	assert.Equal(t, []int{1007, 61593}, p.TeamIDs)
+	assert.Equal(t, "DATABASE_URL", p.EnvironmentVariables[0].Name)
+	assert.Equal(t, "postgres://db/test", p.EnvironmentVariables[0].Value)
+	assert.Equal(t, codeship.NotifierEmail, p.NotificationRules[0].Notifier)
+	assert.Equal(t, 1, p.DeploymentPipelines[0].Position)
+	assert.Equal(t, []string{"./setup", "./seed"}, p.SetupCommands, "setup commands keep their order")
+}
+
+func TestSnapshot_Error(t *testing.T) {
+	_, err := backup.Snapshot(context.Background(), &projecttest.Client{Err: errors.New("boom")}, backup.Options{})
+	assert.EqualError(t, err, "unable to snapshot projects: boom")
+}
+
+func TestMarshal(t *testing.T) {
+	source := projects()
+	snapshot, err := backup.Snapshot(context.Background(), &projecttest.Client{Projects: source}, backup.Options{Redact: true})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		format backup.Format
+		golden string
+	}{
+		{
+			name:   "json",
+			format: backup.FormatJSON,
+			golden: "testdata/snapshot.json",
+		},
+		{
+			name:   "yaml",
+			format: backup.FormatYAML,
+			golden: "testdata/snapshot.yml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := backup.Marshal(snapshot[0], tt.format)
+			require.NoError(t, err)
+
+			if *update {
+				require.NoError(t, ioutil.WriteFile(tt.golden, b, 0644))
+			}
+
+			want, err := ioutil.ReadFile(tt.golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(b))
+
+			for _, secret := range []string{"aeskey", "ssh-rsa", "secret", "postgres://", "hooks.slack.com"} {
+				assert.NotContains(t, string(b), secret)
+			}
+
+			p, err := backup.Unmarshal(b)
+			require.NoError(t, err)
+			assert.Equal(t, snapshot[0], p)
+		})
+	}
+
+	assert.Equal(t, "secret", source[1].EnvironmentVariables[0].Value, "source projects are not modified")
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	_, err := backup.Unmarshal([]byte("name: org/test-project\n"))
+	assert.EqualError(t, err, "invalid snapshot: uuid or repository_url is required")
+
+	_, err = backup.Unmarshal([]byte("team_ids: [one]\nuuid: foo\n"))
+	assert.Error(t, err)
+}
+
+func TestBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	stale := filepath.Join(dir, "3a6b6f3e-1c2d-4e5f-8a9b-0c1d2e3f4a5b.yml")
+	require.NoError(t, ioutil.WriteFile(stale, []byte("uuid: 3a6b6f3e-1c2d-4e5f-8a9b-0c1d2e3f4a5b\n"), 0600))
+
+	// Other files, e.g. in the root of a repository, are neither removed nor loaded
+	others := map[string]string{
+		"README.md":              "backups",
+		"codeship-steps.yml":     "- name: tests\n  service: app\n  command: make test\n",
+		"package.json":           `{"name": "backups"}`,
+		"settings.yaml":          "not: a snapshot\n",
+		"0059df30-notes.json":    "{}",
+		"c38f3280-2d13-0134.yml": "",
+	}
+	for name, content := range others {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+
+	client := &projecttest.Client{Projects: projects()}
+	opts := backup.Options{Format: backup.FormatYAML}
+
+	result, err := backup.Backup(context.Background(), client, dir, opts)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "0059df30-7701-0135-8810-6e5f001a2e3c.yml"),
+		filepath.Join(dir, "c38f3280-2d13-0134-d0b4-1e1b0ba06dd1.yml"),
+	}, result.Written)
+	assert.Equal(t, []string{stale}, result.Removed)
+	for name, content := range others {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(b))
+	}
+
+	tmp, err := filepath.Glob(filepath.Join(dir, ".tmp-*"))
+	require.NoError(t, err)
+	assert.Empty(t, tmp, "temporary files are removed")
+
+	first, err := ioutil.ReadFile(result.Written[0])
+	require.NoError(t, err)
+
+	// Reordering lists does not change the snapshot
+	p := client.Projects[1]
+	p.TeamIDs = []int{1007, 61593}
+	p.NotificationRules = []codeship.NotificationRule{p.NotificationRules[1], p.NotificationRules[0]}
+	client.Projects = []codeship.Project{p, client.Projects[0]}
+
+	result, err = backup.Backup(context.Background(), client, dir, opts)
+	require.NoError(t, err)
+	assert.Empty(t, result.Removed)
+
+	second, err := ioutil.ReadFile(result.Written[0])
+	require.NoError(t, err)
+	assert.Equal(t, string(first), string(second))
+
+	loaded, err := backup.Load(dir)
+	require.NoError(t, err)
+	assert.Len(t, loaded, 2)
+	assert.Equal(t, "0059df30-7701-0135-8810-6e5f001a2e3c", loaded[0].UUID)
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "json", backup.FormatJSON.String())
+	assert.Equal(t, ".json", backup.FormatJSON.Ext())
+	assert.Equal(t, "yaml", backup.FormatYAML.String())
+	assert.Equal(t, ".yml", backup.FormatYAML.Ext())
+}
@@ -0,0 +1,110 @@
+package backup
+
+import (
+	"context"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/projectconfig"
+	"github.com/pkg/errors"
+)
+
+// Client is the subset of *codeship.Organization used to restore projects
+type Client interface {
+	Lister
+	GetProject(ctx context.Context, projectUUID string) (codeship.Project, codeship.Response, error)
+	CreateProject(ctx context.Context, p codeship.ProjectCreateRequest) (codeship.Project, codeship.Response, error)
+	UpdateProject(ctx context.Context, projectUUID string, p codeship.ProjectUpdateRequest) (codeship.Project, codeship.Response, error)
+}
+
+var _ Client = &codeship.Organization{}
+
+// PlanRestore plans restoring projects to the state of their snapshots. A
+// project that still exists is updated, matched by UUID or repository URL,
+// and a project that was deleted is recreated. The plan can be reviewed and
+// then applied with projectconfig.Apply.
+//
+// Secrets that were redacted are left unchanged, and deployment pipelines are
+// not restored. Both are reported as warnings in the plan.
+func PlanRestore(ctx context.Context, client Client, projects []codeship.Project) (projectconfig.Plan, error) {
+	current, err := Snapshot(ctx, client, Options{})
+	if err != nil {
+		return projectconfig.Plan{}, errors.Wrap(err, "unable to plan restore")
+	}
+	exists := make(map[string]bool, len(current))
+	for _, p := range current {
+		exists[p.UUID] = true
+	}
+
+	var c projectconfig.Config
+	warnings := make([][]string, len(projects))
+	for i, p := range projects {
+		desired, w := desiredProject(p)
+		if !exists[p.UUID] {
+			// Deleted projects are recreated with a new UUID
+			desired.UUID = ""
+		}
+		c.Projects = append(c.Projects, desired)
+		warnings[i] = w
+	}
+
+	plan, err := projectconfig.MakePlan(ctx, client, c)
+	if err != nil {
+		return projectconfig.Plan{}, errors.Wrap(err, "unable to plan restore")
+	}
+	for i := range plan.Projects {
+		plan.Projects[i].Warnings = append(plan.Projects[i].Warnings, warnings[i]...)
+	}
+
+	return plan, nil
+}
+
+// desiredProject converts a snapshot to the desired state of a project,
+// leaving fields with redacted values unmanaged
+func desiredProject(p codeship.Project) (projectconfig.Project, []string) {
+	projectType := p.Type
+	desired := projectconfig.Project{
+		UUID:          p.UUID,
+		RepositoryURL: p.RepositoryURL,
+		Type:          &projectType,
+		TeamIDs:       nonNilInts(p.TeamIDs),
+		SetupCommands: nonNilStrings(p.SetupCommands),
+	}
+
+	var warnings []string
+
+	desired.EnvironmentVariables = append([]codeship.EnvironmentVariable{}, p.EnvironmentVariables...)
+	for _, env := range p.EnvironmentVariables {
+		if env.Value == Redacted {
+			desired.EnvironmentVariables = nil
+			warnings = append(warnings, "environment_variables are redacted in the snapshot and will not be restored")
+			break
+		}
+	}
+
+	desired.NotificationRules = append([]codeship.NotificationRule{}, p.NotificationRules...)
+	for _, rule := range p.NotificationRules {
+		if rule.Options.Key == Redacted || rule.Options.URL == Redacted {
+			desired.NotificationRules = nil
+			warnings = append(warnings, "notification_rules are redacted in the snapshot and will not be restored")
+			break
+		}
+	}
+
+	for _, tp := range p.TestPipelines {
+		desired.TestPipelines = append(desired.TestPipelines, codeship.TestPipeline{Name: tp.Name, Commands: tp.Commands})
+	}
+
+	if len(p.DeploymentPipelines) > 0 {
+		warnings = append(warnings, "deployment_pipelines cannot be restored and will not be changed")
+	}
+
+	return desired, warnings
+}
+
+func nonNilInts(ints []int) []int {
+	return append([]int{}, ints...)
+}
+
+func nonNilStrings(strs []string) []string {
+	return append([]string{}, strs...)
+}
@@ -0,0 +1,67 @@
+package backup_test
+
+import (
+	"context"
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/backup"
+	"github.com/codeship/codeship-go/internal/projecttest"
+	"github.com/codeship/codeship-go/projectconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanRestore(t *testing.T) {
+	snapshot, err := backup.Snapshot(context.Background(), &projecttest.Client{Projects: projects()}, backup.Options{})
+	require.NoError(t, err)
+
+	// The first project was changed and the second was deleted since the backup
+	changed := projects()[1]
+	changed.SetupCommands = []string{"./setup"}
+	changed.EnvironmentVariables = changed.EnvironmentVariables[:1]
+	client := &projecttest.Client{Projects: []codeship.Project{changed}}
+
+	plan, err := backup.PlanRestore(context.Background(), client, snapshot)
+	require.NoError(t, err)
+	require.Len(t, plan.Projects, 2)
+
+	assert.Equal(t, projectconfig.ActionUpdate, plan.Projects[0].Action)
+	assert.Equal(t, []string{"deployment_pipelines cannot be restored and will not be changed"}, plan.Projects[0].Warnings)
+	assert.Equal(t, projectconfig.ActionCreate, plan.Projects[1].Action)
+
+	result, err := projectconfig.Apply(context.Background(), client, plan, projectconfig.ApplyOptions{})
+	require.NoError(t, err)
+	assert.Len(t, result.Created, 1)
+	assert.Len(t, result.Updated, 1)
+
+	restored := client.Projects[0]
+	assert.Equal(t, []string{"./setup", "./seed"}, restored.SetupCommands)
+	assert.Len(t, restored.EnvironmentVariables, 2)
+	assert.Equal(t, "https://github.com/org/other-project", client.Projects[1].RepositoryURL)
+	assert.Equal(t, codeship.ProjectTypePro, client.Projects[1].Type)
+
+	plan, err = backup.PlanRestore(context.Background(), client, snapshot)
+	require.NoError(t, err)
+	assert.False(t, plan.HasChanges(), plan.String())
+}
+
+func TestPlanRestore_Redacted(t *testing.T) {
+	snapshot, err := backup.Snapshot(context.Background(), &projecttest.Client{Projects: projects()}, backup.Options{Redact: true})
+	require.NoError(t, err)
+
+	changed := projects()[1]
+	changed.EnvironmentVariables = changed.EnvironmentVariables[:1]
+	client := &projecttest.Client{Projects: []codeship.Project{changed}}
+
+	plan, err := backup.PlanRestore(context.Background(), client, snapshot[:1])
+	require.NoError(t, err)
+	require.Len(t, plan.Projects, 1)
+
+	assert.Equal(t, projectconfig.ActionNone, plan.Projects[0].Action)
+	assert.Equal(t, []string{
+		"environment_variables are redacted in the snapshot and will not be restored",
+		"notification_rules are redacted in the snapshot and will not be restored",
+		"deployment_pipelines cannot be restored and will not be changed",
+	}, plan.Projects[0].Warnings)
+}
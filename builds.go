@@ -9,6 +9,31 @@ import (
 	"github.com/pkg/errors"
 )
 
+// Build statuses reported by Codeship for a Build
+const (
+	BuildStatusInitiated             = "initiated"
+	BuildStatusWaiting               = "waiting"
+	BuildStatusTesting               = "testing"
+	BuildStatusSuccess               = "success"
+	BuildStatusError                 = "error"
+	BuildStatusStopped               = "stopped"
+	BuildStatusIgnored               = "ignored"
+	BuildStatusBlocked               = "blocked"
+	BuildStatusInfrastructureFailure = "infrastructure_failure"
+	BuildStatusSkippedCommit         = "skipped_commit"
+)
+
+// Step statuses reported by Codeship for a BuildStep
+const (
+	StepStatusWaiting               = "waiting"
+	StepStatusRunning               = "running"
+	StepStatusSuccess               = "success"
+	StepStatusError                 = "error"
+	StepStatusInfrastructureFailure = "infrastructure_failure"
+	StepStatusStopped               = "stopped"
+	StepStatusSkipped               = "skipped"
+)
+
 // BuildLinks structure of BuildLinks object for a Build
 type BuildLinks struct {
 	Pipelines string `json:"pipelines,omitempty"`
@@ -34,6 +59,21 @@ type Build struct {
 	UUID             string     `json:"uuid,omitempty"`
 }
 
+// Finished returns true if the build is in a terminal status and will no longer change
+func (b Build) Finished() bool {
+	switch b.Status {
+	case BuildStatusSuccess,
+		BuildStatusError,
+		BuildStatusStopped,
+		BuildStatusIgnored,
+		BuildStatusBlocked,
+		BuildStatusInfrastructureFailure,
+		BuildStatusSkippedCommit:
+		return true
+	}
+	return false
+}
+
 // BuildList holds a list of Build objects
 type BuildList struct {
 	Builds []Build `json:"builds"`
@@ -136,7 +176,7 @@ type buildRequest struct {
 func (o *Organization) CreateBuild(ctx context.Context, projectUUID, ref, commitSha string) (bool, Response, error) {
 	path := fmt.Sprintf("/organizations/%s/projects/%s/builds", o.UUID, projectUUID)
 
-	_, resp, err := o.client.request(ctx, "POST", path, buildRequest{
+	_, resp, err := o.request(ctx, "CreateBuild", "POST", path, buildRequest{
 		Ref:       ref,
 		CommitSha: commitSha,
 	})
@@ -153,7 +193,7 @@ func (o *Organization) CreateBuild(ctx context.Context, projectUUID, ref, commit
 func (o *Organization) GetBuild(ctx context.Context, projectUUID, buildUUID string) (Build, Response, error) {
 	path := fmt.Sprintf("/organizations/%s/projects/%s/builds/%s", o.UUID, projectUUID, buildUUID)
 
-	body, resp, err := o.client.request(ctx, "GET", path, nil)
+	body, resp, err := o.request(ctx, "GetBuild", "GET", path, nil)
 	if err != nil {
 		return Build{}, resp, errors.Wrap(err, "unable to get build")
 	}
@@ -175,7 +215,7 @@ func (o *Organization) ListBuilds(ctx context.Context, projectUUID string, opts
 		return BuildList{}, Response{}, errors.Wrap(err, "unable to list builds")
 	}
 
-	body, resp, err := o.client.request(ctx, "GET", path, nil)
+	body, resp, err := o.request(ctx, "ListBuilds", "GET", path, nil)
 	if err != nil {
 		return BuildList{}, resp, errors.Wrap(err, "unable to list builds")
 	}
@@ -197,7 +237,7 @@ func (o *Organization) ListBuildPipelines(ctx context.Context, projectUUID, buil
 		return BuildPipelines{}, Response{}, errors.Wrap(err, "unable to list pipelines")
 	}
 
-	body, resp, err := o.client.request(ctx, "GET", path, nil)
+	body, resp, err := o.request(ctx, "ListBuildPipelines", "GET", path, nil)
 	if err != nil {
 		return BuildPipelines{}, resp, errors.Wrap(err, "unable to list pipelines")
 	}
@@ -216,7 +256,7 @@ func (o *Organization) ListBuildPipelines(ctx context.Context, projectUUID, buil
 func (o *Organization) StopBuild(ctx context.Context, projectUUID, buildUUID string) (bool, Response, error) {
 	path := fmt.Sprintf("/organizations/%s/projects/%s/builds/%s/stop", o.UUID, projectUUID, buildUUID)
 
-	_, resp, err := o.client.request(ctx, "POST", path, nil)
+	_, resp, err := o.request(ctx, "StopBuild", "POST", path, nil)
 	if err != nil {
 		return false, resp, errors.Wrap(err, "unable to stop build")
 	}
@@ -230,7 +270,7 @@ func (o *Organization) StopBuild(ctx context.Context, projectUUID, buildUUID str
 func (o *Organization) RestartBuild(ctx context.Context, projectUUID, buildUUID string) (bool, Response, error) {
 	path := fmt.Sprintf("/organizations/%s/projects/%s/builds/%s/restart", o.UUID, projectUUID, buildUUID)
 
-	_, resp, err := o.client.request(ctx, "POST", path, nil)
+	_, resp, err := o.request(ctx, "RestartBuild", "POST", path, nil)
 	if err != nil {
 		return false, resp, errors.Wrap(err, "unable to restart build")
 	}
@@ -247,7 +287,7 @@ func (o *Organization) ListBuildServices(ctx context.Context, projectUUID, build
 		return BuildServices{}, Response{}, errors.Wrap(err, "unable to list build services")
 	}
 
-	body, resp, err := o.client.request(ctx, "GET", path, nil)
+	body, resp, err := o.request(ctx, "ListBuildServices", "GET", path, nil)
 	if err != nil {
 		return BuildServices{}, resp, errors.Wrap(err, "unable to list build services")
 	}
@@ -269,7 +309,7 @@ func (o *Organization) ListBuildSteps(ctx context.Context, projectUUID, buildUUI
 		return BuildSteps{}, Response{}, errors.Wrap(err, "unable to list build steps")
 	}
 
-	body, resp, err := o.client.request(ctx, "GET", path, nil)
+	body, resp, err := o.request(ctx, "ListBuildSteps", "GET", path, nil)
 	if err != nil {
 		return BuildSteps{}, resp, errors.Wrap(err, "unable to list build steps")
 	}
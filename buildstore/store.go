@@ -0,0 +1,224 @@
+// Package buildstore keeps a local copy of Codeship builds, along with their
+// steps, services and pipelines, in a JSON Lines file.
+//
+// Builds in a terminal status never change, so after the first sync only new
+// builds and builds that were still running are fetched from the API:
+//
+//	store, err := buildstore.Open("builds.jsonl")
+//	result, err := buildstore.Sync(ctx, org, store, projectUUID)
+//
+//	failed := store.Find(buildstore.Query{Status: codeship.BuildStatusError})
+package buildstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/pkg/errors"
+)
+
+// Record is a build stored locally along with its details. Steps and services
+// are only populated for Pro projects, and pipelines for Basic projects.
+type Record struct {
+	Build     codeship.Build           `json:"build"`
+	Steps     []codeship.BuildStep     `json:"steps,omitempty"`
+	Services  []codeship.BuildService  `json:"services,omitempty"`
+	Pipelines []codeship.BuildPipeline `json:"pipelines,omitempty"`
+	// Complete is true once the build has finished and its details have been fetched
+	Complete bool `json:"complete"`
+	// SyncedAt is the time the record was last fetched from the API
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// Store is a local copy of builds backed by a JSON Lines file, with one Record
+// per line. It is safe for concurrent use.
+type Store struct {
+	mu      sync.RWMutex
+	path    string
+	records map[string]Record
+}
+
+// Open loads the store at path. A missing file is treated as an empty store.
+func Open(path string) (*Store, error) {
+	s := &Store{
+		path:    path,
+		records: make(map[string]Record),
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open build store")
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, errors.Wrapf(err, "unable to read build store line %d", line)
+		}
+		s.records[r.Build.UUID] = r
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "unable to read build store")
+	}
+
+	return s, nil
+}
+
+// Save writes the store to disk, newest builds first. The file is replaced
+// atomically so a failed save never corrupts the existing store.
+func (s *Store) Save() error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-")
+	if err != nil {
+		return errors.Wrap(err, "unable to save build store")
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, r := range sortRecords(s.all()) {
+		if err := enc.Encode(r); err != nil {
+			_ = tmp.Close()
+			return errors.Wrap(err, "unable to save build store")
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "unable to save build store")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "unable to save build store")
+	}
+
+	return errors.Wrap(os.Rename(tmp.Name(), s.path), "unable to save build store")
+}
+
+// Get returns the record for a build UUID
+func (s *Store) Get(buildUUID string) (Record, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.records[buildUUID]
+	return r, ok
+}
+
+// Put adds or replaces the record for a build
+func (s *Store) Put(r Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[r.Build.UUID] = r
+}
+
+// Len returns the number of builds in the store
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.records)
+}
+
+// LatestQueuedAt returns the QueuedAt time of the newest build stored for a project
+func (s *Store) LatestQueuedAt(projectUUID string) time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var latest time.Time
+	for _, r := range s.records {
+		if r.Build.ProjectUUID == projectUUID && r.Build.QueuedAt.After(latest) {
+			latest = r.Build.QueuedAt
+		}
+	}
+	return latest
+}
+
+// Query filters the records returned by Find. Zero values match all records.
+type Query struct {
+	ProjectUUID string
+	Branch      string
+	Status      string
+	Username    string
+	// Since only matches builds queued at or after this time
+	Since time.Time
+	// Until only matches builds queued before this time
+	Until time.Time
+	// Limit caps the number of records returned
+	Limit int
+}
+
+func (q Query) matches(b codeship.Build) bool {
+	switch {
+	case q.ProjectUUID != "" && b.ProjectUUID != q.ProjectUUID,
+		q.Branch != "" && b.Branch != q.Branch,
+		q.Status != "" && b.Status != q.Status,
+		q.Username != "" && b.Username != q.Username,
+		!q.Since.IsZero() && b.QueuedAt.Before(q.Since),
+		!q.Until.IsZero() && !b.QueuedAt.Before(q.Until):
+		return false
+	}
+	return true
+}
+
+// Find returns the records matching the query, newest builds first
+func (s *Store) Find(q Query) []Record {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var records []Record
+	for _, r := range s.records {
+		if q.matches(r.Build) {
+			records = append(records, r)
+		}
+	}
+
+	records = sortRecords(records)
+	if q.Limit > 0 && len(records) > q.Limit {
+		records = records[:q.Limit]
+	}
+	return records
+}
+
+func (s *Store) all() []Record {
+	records := make([]Record, 0, len(s.records))
+	for _, r := range s.records {
+		records = append(records, r)
+	}
+	return records
+}
+
+// sortRecords sorts records newest first, using the UUID to break ties so the
+// order is deterministic
+func sortRecords(records []Record) []Record {
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i].Build, records[j].Build
+		if !a.QueuedAt.Equal(b.QueuedAt) {
+			return a.QueuedAt.After(b.QueuedAt)
+		}
+		return a.UUID < b.UUID
+	})
+	return records
+}
@@ -0,0 +1,121 @@
+package buildstore_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/buildstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "buildstore")
+	require.NoError(t, err)
+	return dir, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func record(uuid, project, branch, status string, queuedAt time.Time) buildstore.Record {
+	return buildstore.Record{
+		Build: codeship.Build{
+			UUID:        uuid,
+			ProjectUUID: project,
+			Branch:      branch,
+			Status:      status,
+			QueuedAt:    queuedAt,
+		},
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	path := filepath.Join(dir, "builds.jsonl")
+
+	store, err := buildstore.Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, 0, store.Len())
+
+	base := time.Date(2017, 9, 13, 17, 0, 0, 0, time.UTC)
+	store.Put(record("b1", "p1", "master", "success", base))
+	store.Put(record("b2", "p1", "feature", "error", base.Add(time.Hour)))
+	store.Put(record("b3", "p1", "master", "testing", base.Add(2*time.Hour)))
+	store.Put(record("b4", "p2", "master", "success", base.Add(3*time.Hour)))
+
+	require.NoError(t, store.Save())
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(b)), "\n"), 4)
+
+	store, err = buildstore.Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, 4, store.Len())
+
+	r, ok := store.Get("b2")
+	require.True(t, ok)
+	assert.Equal(t, "feature", r.Build.Branch)
+
+	assert.Equal(t, base.Add(2*time.Hour), store.LatestQueuedAt("p1"))
+	assert.True(t, store.LatestQueuedAt("p3").IsZero())
+
+	tests := []struct {
+		name  string
+		query buildstore.Query
+		want  []string
+	}{
+		{
+			name: "all, newest first",
+			want: []string{"b4", "b3", "b2", "b1"},
+		},
+		{
+			name:  "project and branch",
+			query: buildstore.Query{ProjectUUID: "p1", Branch: "master"},
+			want:  []string{"b3", "b1"},
+		},
+		{
+			name:  "status",
+			query: buildstore.Query{Status: "error"},
+			want:  []string{"b2"},
+		},
+		{
+			name:  "time range",
+			query: buildstore.Query{Since: base.Add(time.Hour), Until: base.Add(3 * time.Hour)},
+			want:  []string{"b3", "b2"},
+		},
+		{
+			name:  "limit",
+			query: buildstore.Query{Limit: 1},
+			want:  []string{"b4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, r := range store.Find(tt.query) {
+				got = append(got, r.Build.UUID)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	path := filepath.Join(dir, "builds.jsonl")
+	require.NoError(t, ioutil.WriteFile(path, []byte("{\"build\": {}}\nnot json\n"), 0600))
+
+	_, err := buildstore.Open(path)
+	assert.EqualError(t, err, "unable to read build store line 2: invalid character 'o' in literal null (expecting 'u')")
+}
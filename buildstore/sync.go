@@ -0,0 +1,177 @@
+package buildstore
+
+import (
+	"context"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/pkg/errors"
+)
+
+// Client is the subset of *codeship.Organization used to sync builds
+type Client interface {
+	IterateBuilds(ctx context.Context, projectUUID string, opts ...codeship.PaginationOption) *codeship.BuildIterator
+	GetBuild(ctx context.Context, projectUUID, buildUUID string) (codeship.Build, codeship.Response, error)
+	ListBuildSteps(ctx context.Context, projectUUID, buildUUID string, opts ...codeship.PaginationOption) (codeship.BuildSteps, codeship.Response, error)
+	ListBuildServices(ctx context.Context, projectUUID, buildUUID string, opts ...codeship.PaginationOption) (codeship.BuildServices, codeship.Response, error)
+	ListBuildPipelines(ctx context.Context, projectUUID, buildUUID string, opts ...codeship.PaginationOption) (codeship.BuildPipelines, codeship.Response, error)
+}
+
+var _ Client = &codeship.Organization{}
+
+// Result summarizes the changes made by Sync
+type Result struct {
+	// Added is the number of builds that were not in the store before
+	Added int
+	// Refreshed is the number of stored builds that were still running and were fetched again
+	Refreshed int
+	// Completed is the number of builds that finished and had their details fetched
+	Completed int
+}
+
+const perPage = 50
+
+// Sync fetches builds queued since the newest build in the store, refreshes
+// stored builds that had not yet finished, and fetches the steps, services and
+// pipelines of builds once they have finished. Builds are only added to the
+// store and saved once the whole sync succeeds, so a failed sync leaves the
+// store unchanged and can be retried.
+func Sync(ctx context.Context, client Client, store *Store, projectUUID string) (Result, error) {
+	var result Result
+	var staged []Record
+
+	latest := store.LatestQueuedAt(projectUUID)
+	seen := make(map[string]bool)
+
+	it := client.IterateBuilds(ctx, projectUUID, codeship.PerPage(perPage))
+	for it.Next() {
+		build := it.Build()
+
+		// Builds are returned newest first, so everything from here on has
+		// already been synced
+		if !latest.IsZero() && build.QueuedAt.Before(latest) {
+			break
+		}
+		seen[build.UUID] = true
+
+		existing, ok := store.Get(build.UUID)
+		switch {
+		case !ok:
+			result.Added++
+		case existing.Complete:
+			continue
+		default:
+			result.Refreshed++
+		}
+
+		r, err := update(ctx, client, projectUUID, build)
+		if err != nil {
+			return result, err
+		}
+		if r.Complete {
+			result.Completed++
+		}
+		staged = append(staged, r)
+	}
+	if err := it.Err(); err != nil {
+		return result, errors.Wrap(err, "unable to sync builds")
+	}
+
+	for _, r := range store.Find(Query{ProjectUUID: projectUUID}) {
+		if r.Complete || seen[r.Build.UUID] {
+			continue
+		}
+
+		build, _, err := client.GetBuild(ctx, projectUUID, r.Build.UUID)
+		if err != nil {
+			return result, errors.Wrap(err, "unable to sync builds")
+		}
+		result.Refreshed++
+
+		r, err := update(ctx, client, projectUUID, build)
+		if err != nil {
+			return result, err
+		}
+		if r.Complete {
+			result.Completed++
+		}
+		staged = append(staged, r)
+	}
+
+	for _, r := range staged {
+		store.Put(r)
+	}
+
+	return result, store.Save()
+}
+
+// update returns the record of the latest state of a build, fetching its
+// details and marking it complete if it has finished
+func update(ctx context.Context, client Client, projectUUID string, build codeship.Build) (Record, error) {
+	if build.ProjectUUID == "" {
+		build.ProjectUUID = projectUUID
+	}
+
+	r := Record{
+		Build:    build,
+		SyncedAt: time.Now().UTC(),
+	}
+
+	if !build.Finished() {
+		return r, nil
+	}
+
+	if err := fetchDetails(ctx, client, &r); err != nil {
+		return Record{}, errors.Wrapf(err, "unable to sync build %s", build.UUID)
+	}
+
+	r.Complete = true
+	return r, nil
+}
+
+// fetchDetails fetches the steps, services and pipelines of a build, based on
+// the links returned for the build
+func fetchDetails(ctx context.Context, client Client, r *Record) error {
+	projectUUID, buildUUID := r.Build.ProjectUUID, r.Build.UUID
+
+	if r.Build.Links.Steps != "" {
+		for page := 1; page > 0; {
+			steps, resp, err := client.ListBuildSteps(ctx, projectUUID, buildUUID, codeship.Page(page), codeship.PerPage(perPage))
+			if err != nil {
+				return err
+			}
+			r.Steps = append(r.Steps, steps.Steps...)
+			if page, err = resp.NextPage(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if r.Build.Links.Services != "" {
+		for page := 1; page > 0; {
+			services, resp, err := client.ListBuildServices(ctx, projectUUID, buildUUID, codeship.Page(page), codeship.PerPage(perPage))
+			if err != nil {
+				return err
+			}
+			r.Services = append(r.Services, services.Services...)
+			if page, err = resp.NextPage(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if r.Build.Links.Pipelines != "" {
+		for page := 1; page > 0; {
+			pipelines, resp, err := client.ListBuildPipelines(ctx, projectUUID, buildUUID, codeship.Page(page), codeship.PerPage(perPage))
+			if err != nil {
+				return err
+			}
+			r.Pipelines = append(r.Pipelines, pipelines.Pipelines...)
+			if page, err = resp.NextPage(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,240 @@
+package buildstore_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/buildstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	orgUUID     = "28123f10-e33d-5533-b53f-111ef8d7b14f"
+	projectUUID = "0059df30-7701-0135-8810-6e5f001a2e3c"
+	buildsPath  = "/organizations/" + orgUUID + "/projects/" + projectUUID + "/builds"
+)
+
+// fakeAPI serves builds from a mutable list and counts requests by path.
+// Requests to failing paths return an error.
+type fakeAPI struct {
+	mu       sync.Mutex
+	builds   []string
+	requests map[string]int
+	failing  map[string]bool
+}
+
+func build(uuid, status, queuedAt, link string) string {
+	links := ""
+	if link != "" {
+		links = fmt.Sprintf(`, "links": {%q: "https://api.codeship.com/v2%s/%s/%s"}`, link, buildsPath, uuid, link)
+	}
+	return fmt.Sprintf(`{"uuid": %q, "project_uuid": %q, "status": %q, "queued_at": %q%s}`, uuid, projectUUID, status, queuedAt, links)
+}
+
+func (f *fakeAPI) setBuilds(builds ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.builds = builds
+}
+
+func (f *fakeAPI) count(path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[path]
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests[r.URL.Path]++
+	w.Header().Set("Content-Type", "application/json")
+
+	if f.failing[r.URL.Path] {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/auth":
+		fmt.Fprintf(w, `{"access_token": "token", "expires_at": 9999999999, "organizations": [{"name": "codeship", "uuid": %q}]}`, orgUUID)
+	case buildsPath:
+		fmt.Fprint(w, `{"builds": [`)
+		for i, b := range f.builds {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprint(w, b)
+		}
+		fmt.Fprint(w, `]}`)
+	case buildsPath + "/b0":
+		fmt.Fprintf(w, `{"build": %s}`, build("b0", "stopped", "2017-09-13T08:00:00Z", ""))
+	case buildsPath + "/b1/steps":
+		fmt.Fprint(w, `{"steps": [{"uuid": "s1", "name": "test", "status": "success"}]}`)
+	case buildsPath + "/b2/pipelines":
+		fmt.Fprint(w, `{"pipelines": [{"uuid": "p1", "type": "test", "status": "success"}]}`)
+	case buildsPath + "/b3/services":
+		fmt.Fprint(w, `{"services": [{"uuid": "sv1", "name": "app", "status": "finished"}]}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors": ["not found"]}`)
+	}
+}
+
+func TestSync(t *testing.T) {
+	api := &fakeAPI{requests: make(map[string]int)}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client, err := codeship.New(codeship.NewBasicAuth("username", "password"), codeship.BaseURL(server.URL))
+	require.NoError(t, err)
+
+	org, err := client.Organization(context.Background(), "codeship")
+	require.NoError(t, err)
+
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	path := filepath.Join(dir, "builds.jsonl")
+	store, err := buildstore.Open(path)
+	require.NoError(t, err)
+
+	// Initial sync stores everything, fetching details of finished builds only
+	api.setBuilds(
+		build("b2", "testing", "2017-09-13T10:00:00Z", "pipelines"),
+		build("b1", "success", "2017-09-13T09:00:00Z", "steps"),
+	)
+
+	result, err := buildstore.Sync(context.Background(), org, store, projectUUID)
+	require.NoError(t, err)
+	assert.Equal(t, buildstore.Result{Added: 2, Completed: 1}, result)
+
+	r, ok := store.Get("b1")
+	require.True(t, ok)
+	assert.True(t, r.Complete)
+	require.Len(t, r.Steps, 1)
+	assert.Equal(t, "s1", r.Steps[0].UUID)
+
+	r, ok = store.Get("b2")
+	require.True(t, ok)
+	assert.False(t, r.Complete)
+	assert.Empty(t, r.Pipelines)
+	assert.Equal(t, 0, api.count(buildsPath+"/b2/pipelines"))
+
+	// Incremental sync only fetches new and unfinished builds
+	api.setBuilds(
+		build("b3", "success", "2017-09-13T11:00:00Z", "services"),
+		build("b2", "success", "2017-09-13T10:00:00Z", "pipelines"),
+		build("b1", "success", "2017-09-13T09:00:00Z", "steps"),
+	)
+
+	result, err = buildstore.Sync(context.Background(), org, store, projectUUID)
+	require.NoError(t, err)
+	assert.Equal(t, buildstore.Result{Added: 1, Refreshed: 1, Completed: 2}, result)
+	assert.Equal(t, 1, api.count(buildsPath+"/b1/steps"))
+
+	r, ok = store.Get("b2")
+	require.True(t, ok)
+	assert.True(t, r.Complete)
+	require.Len(t, r.Pipelines, 1)
+
+	r, ok = store.Get("b3")
+	require.True(t, ok)
+	require.Len(t, r.Services, 1)
+
+	// Unfinished builds that are no longer on the first page are fetched individually
+	store.Put(buildstore.Record{Build: codeship.Build{
+		UUID:        "b0",
+		ProjectUUID: projectUUID,
+		Status:      "testing",
+		QueuedAt:    time.Date(2017, 9, 13, 8, 0, 0, 0, time.UTC),
+	}})
+
+	result, err = buildstore.Sync(context.Background(), org, store, projectUUID)
+	require.NoError(t, err)
+	assert.Equal(t, buildstore.Result{Refreshed: 1, Completed: 1}, result)
+
+	r, ok = store.Get("b0")
+	require.True(t, ok)
+	assert.Equal(t, "stopped", r.Build.Status)
+	assert.True(t, r.Complete)
+
+	// The store is saved after each sync
+	saved, err := buildstore.Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, 4, saved.Len())
+}
+
+func TestSyncError(t *testing.T) {
+	api := &fakeAPI{requests: make(map[string]int)}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client, err := codeship.New(codeship.NewBasicAuth("username", "password"), codeship.BaseURL(server.URL))
+	require.NoError(t, err)
+
+	org, err := client.Organization(context.Background(), "codeship")
+	require.NoError(t, err)
+
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	store, err := buildstore.Open(filepath.Join(dir, "builds.jsonl"))
+	require.NoError(t, err)
+
+	api.setBuilds(build("b4", "success", "2017-09-13T10:00:00Z", "steps"))
+
+	_, err = buildstore.Sync(context.Background(), org, store, projectUUID)
+	assert.EqualError(t, err, "unable to sync build b4: unable to list build steps: not found")
+}
+
+func TestSyncRetry(t *testing.T) {
+	api := &fakeAPI{requests: make(map[string]int), failing: map[string]bool{buildsPath + "/b1/steps": true}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client, err := codeship.New(codeship.NewBasicAuth("username", "password"), codeship.BaseURL(server.URL))
+	require.NoError(t, err)
+
+	org, err := client.Organization(context.Background(), "codeship")
+	require.NoError(t, err)
+
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	store, err := buildstore.Open(filepath.Join(dir, "builds.jsonl"))
+	require.NoError(t, err)
+
+	api.setBuilds(
+		build("b2", "testing", "2017-09-13T10:00:00Z", "pipelines"),
+		build("b1", "success", "2017-09-13T09:00:00Z", "steps"),
+	)
+
+	// A failed sync leaves the store unchanged, including the newer build
+	_, err = buildstore.Sync(context.Background(), org, store, projectUUID)
+	require.Error(t, err)
+	assert.Equal(t, 0, store.Len())
+	assert.True(t, store.LatestQueuedAt(projectUUID).IsZero())
+
+	// Retrying on the same store syncs the build that failed
+	api.mu.Lock()
+	api.failing = nil
+	api.mu.Unlock()
+
+	result, err := buildstore.Sync(context.Background(), org, store, projectUUID)
+	require.NoError(t, err)
+	assert.Equal(t, buildstore.Result{Added: 2, Completed: 1}, result)
+
+	r, ok := store.Get("b1")
+	require.True(t, ok)
+	assert.True(t, r.Complete)
+	require.Len(t, r.Steps, 1)
+}
@@ -0,0 +1,234 @@
+package codeship
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/codeship/codeship-go/internal/fileutil"
+	"github.com/pkg/errors"
+)
+
+// CachedResponse is an API response stored in a Cache
+type CachedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	// ExpiresAt is set for immutable resources, which are served from the
+	// cache without contacting the API until it has passed
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// Cache stores API responses so that subsequent requests can be made
+// conditionally with If-None-Match and If-Modified-Since headers
+type Cache interface {
+	Get(key string) (CachedResponse, bool)
+	Set(key string, resp CachedResponse)
+	Delete(key string)
+}
+
+// MemoryCache is an in-memory Cache. It is safe for concurrent use.
+type MemoryCache struct {
+	mu        sync.RWMutex
+	responses map[string]CachedResponse
+}
+
+// NewMemoryCache creates a new, empty MemoryCache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		responses: make(map[string]CachedResponse),
+	}
+}
+
+// Get implements Cache
+func (m *MemoryCache) Get(key string) (CachedResponse, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	resp, ok := m.responses[key]
+	return resp, ok
+}
+
+// Set implements Cache
+func (m *MemoryCache) Set(key string, resp CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.responses[key] = resp
+}
+
+// Delete implements Cache
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.responses, key)
+}
+
+// DiskCache is a Cache that stores each response as a JSON file in a directory.
+// Failures to read or write the cache are treated as cache misses.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache creates a DiskCache in dir, creating the directory if needed.
+//
+// Response bodies are stored verbatim, so the cache holds any secrets returned
+// by the API, such as the AES keys, SSH keys and environment variables of
+// projects. Entries are only readable by the current user, but dir should not
+// be shared or checked in.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrap(err, "unable to create cache directory")
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+// Get implements Cache
+func (d *DiskCache) Get(key string) (CachedResponse, bool) {
+	b, err := ioutil.ReadFile(d.path(key))
+	if err != nil {
+		return CachedResponse{}, false
+	}
+
+	var resp CachedResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return CachedResponse{}, false
+	}
+	return resp, true
+}
+
+// Set implements Cache
+func (d *DiskCache) Set(key string, resp CachedResponse) {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+
+	// Readers never see a partial entry
+	_ = fileutil.WriteFileAtomic(d.path(key), b, 0600)
+}
+
+// Delete implements Cache
+func (d *DiskCache) Delete(key string) {
+	_ = os.Remove(d.path(key))
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// cacheResult describes how sendCached served a response
+type cacheResult int
+
+const (
+	// cacheMiss responses were received from the API
+	cacheMiss cacheResult = iota
+	// cacheRevalidated responses were served from the cache after the API
+	// responded with 304 Not Modified
+	cacheRevalidated
+	// cacheHit responses were served from the cache without contacting the API
+	cacheHit
+)
+
+// sendCached sends a request through the response cache, if configured. GET
+// requests are made conditionally when a cached response exists, and a 304 Not
+// Modified response is served from the cache.
+func (c *Client) sendCached(op string, req *http.Request) ([]byte, Response, cacheResult, error) {
+	if c.cache == nil || req.Method != http.MethodGet {
+		body, resp, err := c.send(req)
+		return body, resp, cacheMiss, err
+	}
+
+	key := c.cacheKey(req)
+
+	cached, ok := c.cache.Get(key)
+	if ok {
+		if !cached.ExpiresAt.IsZero() && time.Now().Before(cached.ExpiresAt) {
+			body, resp := cachedResponse(req, cached)
+			return body, resp, cacheHit, nil
+		}
+
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	body, resp, err := c.send(req)
+	if err != nil || resp.Response == nil {
+		return body, resp, cacheMiss, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		body, resp := cachedResponse(req, cached)
+		return body, resp, cacheRevalidated, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return body, resp, cacheMiss, nil
+	}
+
+	entry := CachedResponse{
+		StatusCode: resp.StatusCode,
+		Header:     cloneHeader(resp.Header),
+		Body:       body,
+	}
+
+	if c.immutableTTL > 0 && isImmutable(op, body) {
+		entry.ExpiresAt = time.Now().Add(c.immutableTTL)
+	}
+
+	if entry.Header.Get("ETag") != "" || entry.Header.Get("Last-Modified") != "" || !entry.ExpiresAt.IsZero() {
+		c.cache.Set(key, entry)
+	}
+
+	return body, resp, cacheMiss, nil
+}
+
+// cacheKey identifies a request by its URL and the credentials used to make
+// it, so responses are never shared between users. Credentials are hashed so
+// that they are not stored in the cache.
+func (c *Client) cacheKey(req *http.Request) string {
+	c.cacheScopeOnce.Do(func() {
+		r, _ := http.NewRequest(http.MethodPost, c.baseURL, nil)
+		c.authenticator.SetAuth(r)
+		sum := sha256.Sum256([]byte(r.Header.Get("Authorization")))
+		c.cacheScope = hex.EncodeToString(sum[:])
+	})
+
+	return c.cacheScope + " " + req.Method + " " + req.URL.String()
+}
+
+func cachedResponse(req *http.Request, cached CachedResponse) ([]byte, Response) {
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", cached.StatusCode, http.StatusText(cached.StatusCode)),
+		StatusCode:    cached.StatusCode,
+		Header:        cloneHeader(cached.Header),
+		Body:          ioutil.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       req,
+	}
+	return cached.Body, newResponse(resp)
+}
+
+// isImmutable reports whether the response to an operation will never change
+func isImmutable(op string, body []byte) bool {
+	switch op {
+	case "GetBuild":
+		var build buildResponse
+		return json.Unmarshal(body, &build) == nil && build.Build.Finished()
+	}
+	return false
+}
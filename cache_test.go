@@ -0,0 +1,197 @@
+package codeship_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseCache(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []codeship.Option
+		header      string
+		value       string
+		condition   string
+		requests    int
+		cached      []bool
+		revalidated []bool
+	}{
+		{
+			name:        "etag",
+			header:      "ETag",
+			value:       `"abc"`,
+			condition:   "If-None-Match",
+			requests:    3,
+			cached:      []bool{false, true, true},
+			revalidated: []bool{false, true, true},
+		},
+		{
+			name:        "last modified",
+			header:      "Last-Modified",
+			value:       "Wed, 13 Sep 2017 17:13:55 GMT",
+			condition:   "If-Modified-Since",
+			requests:    3,
+			cached:      []bool{false, true, true},
+			revalidated: []bool{false, true, true},
+		},
+		{
+			name:        "immutable finished build",
+			opts:        []codeship.Option{codeship.ImmutableCacheTTL(time.Hour)},
+			header:      "ETag",
+			value:       `"abc"`,
+			condition:   "If-None-Match",
+			requests:    1,
+			cached:      []bool{false, true, true},
+			revalidated: []bool{false, false, false},
+		},
+		{
+			name:        "no validators",
+			requests:    3,
+			cached:      []bool{false, false, false},
+			revalidated: []bool{false, false, false},
+		},
+	}
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardown := setup()
+			defer teardown()
+
+			var requests int
+			mux.HandleFunc("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/28123f10-e33d-5533-b53f-111ef8d7b14f/builds/25a3dd8c-eb3e-4e75-1298-8cbcbe621342", func(w http.ResponseWriter, r *http.Request) {
+				requests++
+
+				if tt.header != "" {
+					w.Header().Set(tt.header, tt.value)
+				}
+
+				if tt.condition != "" && r.Header.Get(tt.condition) == tt.value {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprint(w, fixture("builds/get.json"))
+			})
+
+			var cached, revalidated []bool
+			opts := append([]codeship.Option{
+				codeship.BaseURL(server.URL),
+				codeship.ResponseCache(codeship.NewMemoryCache()),
+				codeship.Hooks(codeship.Hook{
+					AfterResponse: func(resp *http.Response, info codeship.RequestInfo) {
+						if info.Operation == "GetBuild" {
+							cached = append(cached, info.Cached)
+							revalidated = append(revalidated, info.Revalidated)
+						}
+					},
+				}),
+			}, tt.opts...)
+
+			c, err := codeship.New(codeship.NewBasicAuth("test", "pass"), opts...)
+			require.NoError(err)
+
+			o, err := c.Organization(context.Background(), "codeship")
+			require.NoError(err)
+
+			for i := 0; i < 3; i++ {
+				build, resp, err := o.GetBuild(context.Background(), "28123f10-e33d-5533-b53f-111ef8d7b14f", "25a3dd8c-eb3e-4e75-1298-8cbcbe621342")
+				require.NoError(err)
+				assert.Equal(http.StatusOK, resp.StatusCode)
+				assert.Equal("25a3dd8c-eb3e-4e75-1298-8cbcbe621342", build.UUID)
+			}
+
+			assert.Equal(tt.requests, requests)
+			assert.Equal(tt.cached, cached)
+			assert.Equal(tt.revalidated, revalidated)
+		})
+	}
+}
+
+func TestResponseCacheScope(t *testing.T) {
+	teardown := setup()
+	defer teardown()
+
+	var notModified int
+	mux.HandleFunc("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"abc"`)
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, fixture("projects/list.json"))
+	})
+
+	cache := codeship.NewMemoryCache()
+	for _, user := range []string{"alice", "bob", "alice"} {
+		c, err := codeship.New(codeship.NewBasicAuth(user, "pass"), codeship.BaseURL(server.URL), codeship.ResponseCache(cache))
+		require.NoError(t, err)
+
+		o, err := c.Organization(context.Background(), "codeship")
+		require.NoError(t, err)
+
+		projects, _, err := o.ListProjects(context.Background())
+		require.NoError(t, err)
+		assert.NotEmpty(t, projects.Projects)
+	}
+
+	// Only the second request made as alice is served from the cache
+	assert.Equal(t, 1, notModified)
+}
+
+func TestCaches(t *testing.T) {
+	dir, err := ioutil.TempDir("", "codeship-cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	disk, err := codeship.NewDiskCache(dir)
+	require.NoError(t, err)
+
+	caches := map[string]codeship.Cache{
+		"memory": codeship.NewMemoryCache(),
+		"disk":   disk,
+	}
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			_, ok := cache.Get("key")
+			assert.False(t, ok)
+
+			expiresAt := time.Date(2017, 9, 13, 17, 13, 55, 0, time.UTC)
+			want := codeship.CachedResponse{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Etag": []string{`"abc"`}},
+				Body:       []byte(`{"foo": "bar"}`),
+				ExpiresAt:  expiresAt,
+			}
+			cache.Set("key", want)
+
+			got, ok := cache.Get("key")
+			require.True(t, ok)
+			assert.Equal(t, want.StatusCode, got.StatusCode)
+			assert.Equal(t, want.Header, got.Header)
+			assert.Equal(t, want.Body, got.Body)
+			assert.True(t, want.ExpiresAt.Equal(got.ExpiresAt))
+
+			cache.Delete("key")
+			_, ok = cache.Get("key")
+			assert.False(t, ok)
+		})
+	}
+}
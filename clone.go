@@ -0,0 +1,119 @@
+package codeship
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// CloneOptions configures CloneProject
+type CloneOptions struct {
+	// Source is the organization that owns the project being cloned. It
+	// defaults to the organization the project is cloned into.
+	Source *Organization
+	// ExcludeSecrets omits environment variables, deployment pipelines and
+	// notification options, which may contain credentials. Notification rules
+	// that cannot be sent without their options are omitted.
+	ExcludeSecrets bool
+	// TeamIDs replaces the teams of the source project. Teams are only copied
+	// when cloning within an organization, as team IDs are not shared between
+	// organizations.
+	TeamIDs []int
+	// Rewrite, if set, is called with each environment variable value, setup
+	// command and test pipeline command and returns the value to use instead.
+	// Field is named as in a Change, e.g. environment_variables[DATABASE_URL].
+	Rewrite func(field, value string) string
+}
+
+// CloneProject creates a project for repositoryURL with the settings of an
+// existing project: its type, teams, setup commands, environment variables,
+// notification rules and pipelines.
+func (o *Organization) CloneProject(ctx context.Context, sourceUUID, repositoryURL string, opts CloneOptions) (Project, Response, error) {
+	if repositoryURL == "" {
+		return Project{}, Response{}, errors.New("unable to clone project: repository url is required")
+	}
+
+	source := opts.Source
+	if source == nil {
+		source = o
+	}
+
+	project, resp, err := source.GetProject(ctx, sourceUUID)
+	if err != nil {
+		return Project{}, resp, errors.Wrap(err, "unable to clone project")
+	}
+
+	req := CloneRequest(project, repositoryURL, opts)
+	if opts.TeamIDs == nil && source.UUID != o.UUID {
+		req.TeamIDs = nil
+	}
+
+	return o.CreateProject(ctx, req)
+}
+
+// CloneRequest builds the request CloneProject uses to create a copy of
+// project for repositoryURL. IDs of pipelines are not copied. opts.Source is
+// ignored, so team IDs are copied unless replaced by opts.TeamIDs.
+func CloneRequest(project Project, repositoryURL string, opts CloneOptions) ProjectCreateRequest {
+	rewrite := opts.Rewrite
+	if rewrite == nil {
+		rewrite = func(_, value string) string { return value }
+	}
+
+	req := ProjectCreateRequest{
+		RepositoryURL: repositoryURL,
+		Type:          project.Type,
+		TeamIDs:       append([]int(nil), project.TeamIDs...),
+	}
+	if opts.TeamIDs != nil {
+		req.TeamIDs = append([]int(nil), opts.TeamIDs...)
+	}
+
+	for _, cmd := range project.SetupCommands {
+		req.SetupCommands = append(req.SetupCommands, rewrite("setup_commands", cmd))
+	}
+
+	for _, p := range project.TestPipelines {
+		pipeline := TestPipeline{Name: p.Name}
+		for _, cmd := range p.Commands {
+			pipeline.Commands = append(pipeline.Commands, rewrite("test_pipelines["+p.Name+"]", cmd))
+		}
+		req.TestPipelines = append(req.TestPipelines, pipeline)
+	}
+
+	for _, rule := range project.NotificationRules {
+		rule.BuildStatuses = append([]NotifyOn(nil), rule.BuildStatuses...)
+		if opts.ExcludeSecrets {
+			valid := rule.Validate() == nil
+			rule.Options = NotificationOptions{Room: rule.Options.Room}
+			if valid && rule.Validate() != nil {
+				continue
+			}
+		}
+		req.NotificationRules = append(req.NotificationRules, rule)
+	}
+
+	if opts.ExcludeSecrets {
+		return req
+	}
+
+	for _, env := range project.EnvironmentVariables {
+		req.EnvironmentVariables = append(req.EnvironmentVariables, EnvironmentVariable{
+			Name:  env.Name,
+			Value: rewrite("environment_variables["+env.Name+"]", env.Value),
+		})
+	}
+
+	for _, p := range project.DeploymentPipelines {
+		pipeline := DeploymentPipeline{Branch: p.Branch, Position: p.Position}
+		if p.Config != nil {
+			pipeline.Config = make(map[string]interface{}, len(p.Config))
+			for k, v := range p.Config {
+				pipeline.Config[k] = v
+			}
+		}
+		req.DeploymentPipelines = append(req.DeploymentPipelines, pipeline)
+	}
+
+	return req
+}
@@ -0,0 +1,231 @@
+package codeship_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneRequest(t *testing.T) {
+	source := codeship.Project{
+		AesKey: "aeskey",
+		DeploymentPipelines: []codeship.DeploymentPipeline{
+			{ID: 1, Branch: codeship.DeploymentBranch{BranchName: "master", MatchMode: "exact"}, Config: map[string]interface{}{"api_key": "secret"}, Position: 1},
+		},
+		EnvironmentVariables: []codeship.EnvironmentVariable{
+			{Name: "DATABASE_URL", Value: "postgres://db/template"},
+		},
+		ID:   1,
+		Name: "org/template",
+		NotificationRules: []codeship.NotificationRule{
+			codeship.EmailNotification(codeship.NotificationTargetCommitter, codeship.NotifyOnFailed),
+			codeship.SlackNotification("https://hooks.slack.com/services/T000/B000/XXX", codeship.NotifyOnFailed),
+		},
+		RepositoryURL: "https://github.com/org/template",
+		SetupCommands: []string{"./setup template"},
+		SSHKey:        "ssh-rsa key",
+		TeamIDs:       []int{1007},
+		TestPipelines: []codeship.TestPipeline{
+			{ID: 1, Name: "tests", Commands: []string{"make test NAME=template"}},
+		},
+		Type: codeship.ProjectTypeBasic,
+		UUID: "0059df30-7701-0135-8810-6e5f001a2e3c",
+	}
+
+	tests := []struct {
+		name string
+		opts codeship.CloneOptions
+		want codeship.ProjectCreateRequest
+	}{
+		{
+			name: "copies settings",
+			want: codeship.ProjectCreateRequest{
+				DeploymentPipelines: []codeship.DeploymentPipeline{
+					{Branch: codeship.DeploymentBranch{BranchName: "master", MatchMode: "exact"}, Config: map[string]interface{}{"api_key": "secret"}, Position: 1},
+				},
+				EnvironmentVariables: []codeship.EnvironmentVariable{
+					{Name: "DATABASE_URL", Value: "postgres://db/template"},
+				},
+				NotificationRules: source.NotificationRules,
+				RepositoryURL:     "https://github.com/org/service",
+				SetupCommands:     []string{"./setup template"},
+				TeamIDs:           []int{1007},
+				TestPipelines: []codeship.TestPipeline{
+					{Name: "tests", Commands: []string{"make test NAME=template"}},
+				},
+				Type: codeship.ProjectTypeBasic,
+			},
+		},
+		{
+			name: "exclude secrets",
+			opts: codeship.CloneOptions{ExcludeSecrets: true},
+			want: codeship.ProjectCreateRequest{
+				NotificationRules: source.NotificationRules[:1],
+				RepositoryURL:     "https://github.com/org/service",
+				SetupCommands:     []string{"./setup template"},
+				TeamIDs:           []int{1007},
+				TestPipelines: []codeship.TestPipeline{
+					{Name: "tests", Commands: []string{"make test NAME=template"}},
+				},
+				Type: codeship.ProjectTypeBasic,
+			},
+		},
+		{
+			name: "rewrite and replace teams",
+			opts: codeship.CloneOptions{
+				TeamIDs: []int{61593},
+				Rewrite: func(field, value string) string {
+					if field == "environment_variables[DATABASE_URL]" {
+						return "postgres://db/service"
+					}
+					return strings.Replace(value, "template", "service", -1)
+				},
+			},
+			want: codeship.ProjectCreateRequest{
+				DeploymentPipelines: []codeship.DeploymentPipeline{
+					{Branch: codeship.DeploymentBranch{BranchName: "master", MatchMode: "exact"}, Config: map[string]interface{}{"api_key": "secret"}, Position: 1},
+				},
+				EnvironmentVariables: []codeship.EnvironmentVariable{
+					{Name: "DATABASE_URL", Value: "postgres://db/service"},
+				},
+				NotificationRules: source.NotificationRules,
+				RepositoryURL:     "https://github.com/org/service",
+				SetupCommands:     []string{"./setup service"},
+				TeamIDs:           []int{61593},
+				TestPipelines: []codeship.TestPipeline{
+					{Name: "tests", Commands: []string{"make test NAME=service"}},
+				},
+				Type: codeship.ProjectTypeBasic,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := codeship.CloneRequest(source, "https://github.com/org/service", tt.opts)
+			assert.Equal(t, tt.want, req)
+
+			req.TeamIDs[0] = 0
+			req.TestPipelines[0].Commands[0] = ""
+			assert.Equal(t, 1007, source.TeamIDs[0])
+			assert.Equal(t, "make test NAME=template", source.TestPipelines[0].Commands[0])
+		})
+	}
+}
+
+func TestCloneProject(t *testing.T) {
+	sourceUUID := "0059df30-7701-0135-8810-6e5f001a2e3c"
+
+	tests := []struct {
+		name          string
+		repositoryURL string
+		status        int
+		err           string
+	}{
+		{
+			name:          "success",
+			repositoryURL: "git@github.com/org/service",
+			status:        http.StatusOK,
+		},
+		{
+			name:          "requires repository url",
+			repositoryURL: "",
+			err:           "unable to clone project: repository url is required",
+		},
+		{
+			name:          "source not found",
+			repositoryURL: "git@github.com/org/service",
+			status:        http.StatusNotFound,
+			err:           "unable to clone project: unable to get project: project not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardown := setup()
+			defer teardown()
+
+			var created codeship.ProjectCreateRequest
+			mux.HandleFunc(fmt.Sprintf("/organizations/%s/projects/%s", org.UUID, sourceUUID), func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "GET", r.Method)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				if tt.status == http.StatusNotFound {
+					fmt.Fprintf(w, fixture("not_found.json"), "project")
+					return
+				}
+				fmt.Fprint(w, fixture("projects/get.json"))
+			})
+			mux.HandleFunc(fmt.Sprintf("/organizations/%s/projects", org.UUID), func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "POST", r.Method)
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprint(w, fixture("projects/create_pro.json"))
+			})
+
+			project, _, err := org.CloneProject(context.Background(), sourceUUID, tt.repositoryURL, codeship.CloneOptions{})
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				assert.Empty(t, created.RepositoryURL)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "org/example-repo", project.Name)
+			assert.Equal(t, tt.repositoryURL, created.RepositoryURL)
+			assert.Equal(t, codeship.ProjectTypePro, created.Type)
+			assert.Equal(t, []int{1007}, created.TeamIDs)
+			assert.Len(t, created.NotificationRules, 2)
+		})
+	}
+}
+
+func TestCloneProject_AcrossOrganizations(t *testing.T) {
+	sourceUUID := "0059df30-7701-0135-8810-6e5f001a2e3c"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, fixture("auth/multiple_organizations.json"))
+	})
+
+	client, err := codeship.New(codeship.NewBasicAuth("test", "pass"), codeship.BaseURL(server.URL))
+	require.NoError(t, err)
+	target, err := client.Organization(context.Background(), "codeship")
+	require.NoError(t, err)
+	templates, err := client.Organization(context.Background(), "templates")
+	require.NoError(t, err)
+
+	mux.HandleFunc(fmt.Sprintf("/organizations/%s/projects/%s", templates.UUID, sourceUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, fixture("projects/get.json"))
+	})
+
+	var created codeship.ProjectCreateRequest
+	mux.HandleFunc(fmt.Sprintf("/organizations/%s/projects", target.UUID), func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, fixture("projects/create_pro.json"))
+	})
+
+	_, _, err = target.CloneProject(context.Background(), sourceUUID, "git@github.com/org/service", codeship.CloneOptions{
+		Source: templates,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "git@github.com/org/service", created.RepositoryURL)
+	assert.Empty(t, created.TeamIDs, "team ids are not shared between organizations")
+	assert.Len(t, created.NotificationRules, 2)
+}
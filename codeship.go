@@ -9,10 +9,10 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
-	"net/http/httputil"
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -101,10 +101,27 @@ type Client struct {
 	baseURL        string
 	authenticator  Authenticator
 	authentication Authentication
-	headers        http.Header
-	httpClient     *http.Client
-	logger         StdLogger
-	verbose        bool
+	// authMu serializes authentication, so that concurrent requests finding
+	// the token expired only authenticate once
+	authMu sync.Mutex
+	// authenticationMu guards authentication
+	authenticationMu sync.RWMutex
+	cache            Cache
+	cacheScope       string
+	cacheScopeOnce   sync.Once
+	headers          http.Header
+	hooks            []Hook
+	httpClient       *http.Client
+	immutableTTL     time.Duration
+	leveledLogger    LeveledLogger
+	logger           StdLogger
+	projectCache     *projectCache
+	projectCacheTTL  time.Duration
+	redactedFields   []string
+	redactedHeaders  []string
+	skipScopeCheck   bool
+	tracer           Tracer
+	verbose          bool
 }
 
 // New creates a new Codeship API client
@@ -114,9 +131,13 @@ func New(auth Authenticator, opts ...Option) (*Client, error) {
 	}
 
 	client := &Client{
-		authenticator: auth,
-		baseURL:       apiURL,
-		headers:       make(http.Header),
+		authenticator:   auth,
+		baseURL:         apiURL,
+		headers:         make(http.Header),
+		projectCache:    &projectCache{entries: make(map[string]projectCacheEntry)},
+		projectCacheTTL: 5 * time.Minute,
+		redactedFields:  defaultRedactedFields,
+		redactedHeaders: defaultRedactedHeaders,
 	}
 
 	if err := client.parseOptions(opts...); err != nil {
@@ -148,13 +169,12 @@ func (c *Client) Organization(ctx context.Context, name string) (*Organization,
 		return nil, errors.New("no organization name provided")
 	}
 
-	if c.AuthenticationRequired() {
-		if _, err := c.Authenticate(ctx); err != nil {
-			return nil, errors.Wrap(err, "authentication failed")
-		}
+	auth, err := c.authenticated(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "authentication failed")
 	}
 
-	for _, org := range c.authentication.Organizations {
+	for _, org := range auth.Organizations {
 		if org.Name == strings.ToLower(name) {
 			return &Organization{
 				UUID:   org.UUID,
@@ -164,20 +184,59 @@ func (c *Client) Organization(ctx context.Context, name string) (*Organization,
 			}, nil
 		}
 	}
-	return nil, ErrUnauthorized(fmt.Sprintf("organization %q not authorized. Authorized organizations: %v", name, c.authentication.Organizations))
+	return nil, ErrUnauthorized(fmt.Sprintf("organization %q not authorized. Authorized organizations: %v", name, auth.Organizations))
 }
 
 // Authentication returns the client's current Authentication object
 func (c *Client) Authentication() Authentication {
+	c.authenticationMu.RLock()
+	defer c.authenticationMu.RUnlock()
+
 	return c.authentication
 }
 
 // AuthenticationRequired determines if a client must authenticate before making a request
 func (c *Client) AuthenticationRequired() bool {
-	return c.authentication.AccessToken == "" || c.authentication.ExpiresAt <= time.Now().Unix()
+	return c.Authentication().expired()
+}
+
+func (a Authentication) expired() bool {
+	return a.AccessToken == "" || a.ExpiresAt <= time.Now().Unix()
+}
+
+func (c *Client) setAuthentication(auth Authentication) {
+	c.authenticationMu.Lock()
+	defer c.authenticationMu.Unlock()
+
+	c.authentication = auth
+}
+
+// authenticated returns the current Authentication, authenticating first if
+// it expired. It is safe for concurrent use: only one goroutine authenticates
+// while the others wait for its result.
+func (c *Client) authenticated(ctx context.Context) (Authentication, error) {
+	if auth := c.Authentication(); !auth.expired() {
+		return auth, nil
+	}
+
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	// Another goroutine may have authenticated while waiting for the lock
+	if auth := c.Authentication(); !auth.expired() {
+		return auth, nil
+	}
+
+	auth, _, err := c.authenticate(ctx)
+	return auth, err
 }
 
-func (c *Client) request(ctx context.Context, method, path string, params interface{}) ([]byte, Response, error) {
+func (c *Client) request(ctx context.Context, op, method, path string, params interface{}) (body []byte, resp Response, err error) {
+	ctx, span := c.startSpan(ctx, op, method, path)
+	defer func() {
+		endSpan(span, resp, err)
+	}()
+
 	url := c.baseURL + path
 	// Replace nil with a JSON object if needed
 	var reqBody io.Reader
@@ -189,10 +248,9 @@ func (c *Client) request(ctx context.Context, method, path string, params interf
 		reqBody = buf
 	}
 
-	if c.AuthenticationRequired() {
-		if _, err := c.Authenticate(ctx); err != nil {
-			return nil, Response{}, err
-		}
+	auth, err := c.authenticated(ctx)
+	if err != nil {
+		return nil, Response{}, err
 	}
 
 	req, err := http.NewRequest(method, url, reqBody)
@@ -202,17 +260,47 @@ func (c *Client) request(ctx context.Context, method, path string, params interf
 
 	// Apply any user-defined headers first
 	req.Header = cloneHeader(c.headers)
-	req.Header.Set("Authorization", "Bearer "+c.authentication.AccessToken)
+	req.Header.Set("Authorization", "Bearer "+auth.AccessToken)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 
-	return c.do(req.WithContext(ctx))
+	return c.do(op, req.WithContext(ctx))
 }
 
-func (c *Client) do(req *http.Request) ([]byte, Response, error) {
+func (c *Client) do(op string, req *http.Request) ([]byte, Response, error) {
+	info := RequestInfo{
+		Operation: op,
+		Method:    req.Method,
+		Path:      strings.TrimPrefix(req.URL.String(), c.baseURL),
+		Attempt:   1,
+	}
+
+	if c.tracer != nil {
+		c.tracer.Inject(req.Context(), req.Header)
+	}
+
+	c.runBefore(req, info)
+
+	start := time.Now()
+	body, resp, result, err := c.sendCached(op, req)
+
+	info.Duration = time.Since(start)
+	info.Cached = result != cacheMiss
+	info.Revalidated = result == cacheRevalidated
+	info.Err = err
+	if resp.Response != nil {
+		info.StatusCode = resp.StatusCode
+	}
+
+	c.runAfter(resp.Response, info)
+	c.logRequest(resp.Response, info)
+
+	return body, resp, err
+}
+
+func (c *Client) send(req *http.Request) ([]byte, Response, error) {
 	if c.verbose {
-		dumpReq, _ := httputil.DumpRequest(req, true)
-		c.logger.Println(string(dumpReq))
+		c.logger.Println(c.dumpRequest(req))
 	}
 
 	resp, err := c.httpClient.Do(req)
@@ -220,11 +308,6 @@ func (c *Client) do(req *http.Request) ([]byte, Response, error) {
 		return nil, Response{}, errors.Wrap(err, "HTTP request failed")
 	}
 
-	if c.verbose {
-		dumpResp, _ := httputil.DumpResponse(resp, true)
-		c.logger.Println(string(dumpResp))
-	}
-
 	defer func() {
 		_ = resp.Body.Close()
 	}()
@@ -232,6 +315,11 @@ func (c *Client) do(req *http.Request) ([]byte, Response, error) {
 	response := newResponse(resp)
 
 	body, err := ioutil.ReadAll(resp.Body)
+
+	if c.verbose {
+		c.logger.Println(c.dumpResponse(resp, body))
+	}
+
 	if err != nil {
 		return nil, response, errors.Wrap(err, "could not read response body")
 	}
@@ -0,0 +1,124 @@
+// Package config parses and validates Codeship Pro codeship-services.yml and
+// codeship-steps.yml files.
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Default file names of the services and steps files
+const (
+	ServicesFile = "codeship-services.yml"
+	StepsFile    = "codeship-steps.yml"
+)
+
+// Position is a location in a configuration file
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	switch {
+	case p.Line == 0:
+		return p.File
+	case p.Column == 0:
+		return fmt.Sprintf("%s:%d", p.File, p.Line)
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+func position(file string, n *yaml.Node) Position {
+	return Position{File: file, Line: n.Line, Column: n.Column}
+}
+
+// Error is an error found at a position in a configuration file
+type Error struct {
+	Pos Position
+	Msg string
+}
+
+func (e Error) Error() string {
+	return e.Pos.String() + ": " + e.Msg
+}
+
+// Errors holds all errors found while parsing or validating configuration
+type Errors []Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e *Errors) add(pos Position, format string, args ...interface{}) {
+	*e = append(*e, Error{Pos: pos, Msg: fmt.Sprintf(format, args...)})
+}
+
+func (e Errors) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Config is a parsed pair of services and steps files
+type Config struct {
+	Services *Services
+	Steps    *Steps
+}
+
+// Load reads, parses and validates codeship-services.yml and
+// codeship-steps.yml from dir
+func Load(dir string) (*Config, error) {
+	servicesPath := filepath.Join(dir, ServicesFile)
+	b, err := ioutil.ReadFile(servicesPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read services")
+	}
+
+	services, err := ParseServices(servicesPath, b)
+	if err != nil {
+		return nil, err
+	}
+
+	stepsPath := filepath.Join(dir, StepsFile)
+	b, err = ioutil.ReadFile(stepsPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read steps")
+	}
+
+	steps, err := ParseSteps(stepsPath, b)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Config{
+		Services: services,
+		Steps:    steps,
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Validate checks the services and steps, including that every step refers to
+// a defined service. It returns Errors if any are found.
+func (c *Config) Validate() error {
+	var errs Errors
+	errs = append(errs, c.Services.validate()...)
+	errs = append(errs, c.Steps.validate(c.Services)...)
+	return errs.err()
+}
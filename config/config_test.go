@@ -0,0 +1,63 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/codeship/codeship-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	c, err := config.Load("..")
+	require.NoError(t, err)
+
+	require.Len(t, c.Services.Services, 3)
+	assert.Equal(t, "integration", c.Services.Services[0].Name)
+	assert.Equal(t, []string{"integration.env.encrypted"}, c.Services.Services[0].EncryptedEnvFile)
+
+	gov, ok := c.Services.Get("gov")
+	require.True(t, ok)
+	assert.Equal(t, &config.Build{Context: ".", Dockerfile: "./docker/gov/Dockerfile"}, gov.Build)
+
+	require.Len(t, c.Steps.Steps, 4)
+	assert.Equal(t, config.StepTypeParallel, c.Steps.Steps[0].Type)
+	assert.Len(t, c.Steps.Steps[0].Steps, 2)
+	assert.Equal(t, `(\d+(\.\d+)+)`, c.Steps.Steps[3].Tag)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	_, err := config.Load("testdata/invalid")
+	require.Error(t, err)
+
+	errs, ok := err.(config.Errors)
+	require.True(t, ok, "expected Errors, got %T", err)
+
+	assert.Equal(t, config.Errors{
+		{
+			Pos: config.Position{File: "testdata/invalid/codeship-services.yml", Line: 1, Column: 1},
+			Msg: `service "app" links refers to undefined service "redis"`,
+		},
+		{
+			Pos: config.Position{File: "testdata/invalid/codeship-steps.yml", Line: 6, Column: 5},
+			Msg: `step "lint" refers to undefined service "linter"`,
+		},
+		{
+			Pos: config.Position{File: "testdata/invalid/codeship-steps.yml", Line: 10, Column: 3},
+			Msg: "step \"deploy\" has an invalid tag: error parsing regexp: missing closing ): `(master`",
+		},
+	}, errs)
+
+	assert.Contains(t, err.Error(), "testdata/invalid/codeship-steps.yml:6:5: step \"lint\" refers to undefined service \"linter\"")
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	_, err := config.Load("testdata")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to read services")
+}
+
+func TestPosition_String(t *testing.T) {
+	assert.Equal(t, "codeship-steps.yml:3:5", config.Position{File: "codeship-steps.yml", Line: 3, Column: 5}.String())
+	assert.Equal(t, "codeship-steps.yml", config.Position{File: "codeship-steps.yml"}.String())
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"regexp"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/pkg/errors"
+)
+
+// Predict returns the steps that will run for a build of ref, a branch or tag
+// name. A step runs if its tag matches ref and its exclude does not, and the
+// same applies to every group containing it. As with Codeship, tag and exclude
+// are not anchored, so "master" also matches "master-fix".
+//
+// Groups are kept with only their nested steps that run, and are dropped if
+// none of them do.
+func (s *Steps) Predict(ref string) ([]Step, error) {
+	return predict(s.Steps, ref)
+}
+
+func predict(steps []Step, ref string) ([]Step, error) {
+	var predicted []Step
+	for _, step := range steps {
+		runs, err := step.runsFor(ref)
+		if err != nil {
+			return nil, err
+		}
+		if !runs {
+			continue
+		}
+
+		if step.IsGroup() {
+			children, err := predict(step.Steps, ref)
+			if err != nil {
+				return nil, err
+			}
+			if len(children) == 0 {
+				continue
+			}
+			step.Steps = children
+		}
+
+		predicted = append(predicted, step)
+	}
+	return predicted, nil
+}
+
+func (s Step) runsFor(ref string) (bool, error) {
+	if s.Tag != "" {
+		re, err := regexp.Compile(s.Tag)
+		if err != nil {
+			return false, errors.Wrapf(err, "%s: invalid tag", s.Pos)
+		}
+		if !re.MatchString(ref) {
+			return false, nil
+		}
+	}
+
+	if s.Exclude != "" {
+		re, err := regexp.Compile(s.Exclude)
+		if err != nil {
+			return false, errors.Wrapf(err, "%s: invalid exclude", s.Pos)
+		}
+		if re.MatchString(ref) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Comparison is the difference between predicted steps and the steps of a build
+type Comparison struct {
+	// Missing are predicted steps that did not run
+	Missing []Step
+	// Unexpected are steps that ran but were not predicted
+	Unexpected []codeship.BuildStep
+}
+
+// Matches reports whether the build ran exactly the predicted steps
+func (c Comparison) Matches() bool {
+	return len(c.Missing) == 0 && len(c.Unexpected) == 0
+}
+
+// Compare compares predicted steps, as returned by Predict, with the steps of
+// a build returned by ListBuildSteps. Only run and push steps are compared,
+// matched by name or, for unnamed steps, by command. Skipped build steps are
+// treated as not having run.
+func Compare(predicted []Step, actual []codeship.BuildStep) Comparison {
+	var c Comparison
+
+	ran := flattenBuildSteps(actual)
+	expected := flattenSteps(predicted)
+
+	// Count the steps on each side, then consume matches in order so that
+	// steps appearing more than once are compared by number
+	ranCount := make(map[string]int, len(ran))
+	for _, step := range ran {
+		ranCount[stepKey(step.Name, step.Command)]++
+	}
+	expectedCount := make(map[string]int, len(expected))
+	for _, step := range expected {
+		expectedCount[stepKey(step.Name, step.Command)]++
+	}
+
+	for _, step := range expected {
+		key := stepKey(step.Name, step.Command)
+		if ranCount[key] == 0 {
+			c.Missing = append(c.Missing, step)
+			continue
+		}
+		ranCount[key]--
+	}
+
+	for _, step := range ran {
+		key := stepKey(step.Name, step.Command)
+		if expectedCount[key] == 0 {
+			c.Unexpected = append(c.Unexpected, step)
+			continue
+		}
+		expectedCount[key]--
+	}
+
+	return c
+}
+
+func stepKey(name, command string) string {
+	if name != "" {
+		return "name " + name
+	}
+	return "command " + command
+}
+
+// flattenSteps returns the run and push steps nested in steps
+func flattenSteps(steps []Step) []Step {
+	var flat []Step
+	for _, step := range steps {
+		if step.IsGroup() {
+			flat = append(flat, flattenSteps(step.Steps)...)
+			continue
+		}
+		flat = append(flat, step)
+	}
+	return flat
+}
+
+// flattenBuildSteps returns the steps nested in steps that were not skipped
+// and are not groups
+func flattenBuildSteps(steps []codeship.BuildStep) []codeship.BuildStep {
+	var flat []codeship.BuildStep
+	for _, step := range steps {
+		if len(step.Steps) > 0 || step.Type == StepTypeSerial || step.Type == StepTypeParallel {
+			flat = append(flat, flattenBuildSteps(step.Steps)...)
+			continue
+		}
+		if step.Status == codeship.StepStatusSkipped {
+			continue
+		}
+		flat = append(flat, step)
+	}
+	return flat
+}
@@ -0,0 +1,155 @@
+package config_test
+
+import (
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stepNames(steps []config.Step) []string {
+	var names []string
+	for _, step := range steps {
+		if step.IsGroup() {
+			names = append(names, step.Name+"/")
+			for _, child := range stepNames(step.Steps) {
+				names = append(names, step.Name+"/"+child)
+			}
+			continue
+		}
+		names = append(names, step.Name)
+	}
+	return names
+}
+
+func TestSteps_Predict(t *testing.T) {
+	steps, err := config.ParseSteps("codeship-steps.yml", []byte(`
+- name: tests
+  type: parallel
+  service: app
+  exclude: ^docs/
+  steps:
+  - name: unit
+    command: ./scripts/unit
+  - name: e2e
+    tag: ^(master|release/.*)$
+    command: ./scripts/e2e
+- name: deploy
+  type: serial
+  service: app
+  tag: ^master$
+  steps:
+  - name: migrate
+    command: ./scripts/migrate
+  - name: push
+    type: push
+    image_name: org/app
+- name: release
+  service: app
+  tag: v\d+
+  command: ./scripts/release
+`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		ref   string
+		steps []string
+	}{
+		{
+			name:  "master",
+			ref:   "master",
+			steps: []string{"tests/", "tests/unit", "tests/e2e", "deploy/", "deploy/migrate", "deploy/push"},
+		},
+		{
+			name:  "feature branch",
+			ref:   "feature/login",
+			steps: []string{"tests/", "tests/unit"},
+		},
+		{
+			name: "excluded branch",
+			ref:  "docs/readme",
+		},
+		{
+			name:  "tag matches unanchored",
+			ref:   "release-v1",
+			steps: []string{"tests/", "tests/unit", "release"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			predicted, err := steps.Predict(tt.ref)
+			require.NoError(t, err)
+			assert.Equal(t, tt.steps, stepNames(predicted))
+		})
+	}
+}
+
+func TestSteps_Predict_InvalidTag(t *testing.T) {
+	steps, err := config.ParseSteps("codeship-steps.yml", []byte("- service: app\n  tag: \"(\"\n"))
+	require.NoError(t, err)
+
+	_, err = steps.Predict("master")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "codeship-steps.yml:1:3: invalid tag")
+}
+
+func TestCompare(t *testing.T) {
+	c, err := config.Load("..")
+	require.NoError(t, err)
+
+	predicted, err := c.Steps.Predict("master")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		actual     []codeship.BuildStep
+		missing    []string
+		unexpected []string
+	}{
+		{
+			name: "matches",
+			actual: []codeship.BuildStep{
+				{
+					Name: "multi-version tests",
+					Type: "parallel",
+					Steps: []codeship.BuildStep{
+						{Name: "1.13.15 test", Type: "run", Status: codeship.StepStatusSuccess},
+						{Name: "1.14.10 test", Type: "run", Status: codeship.StepStatusSuccess},
+					},
+				},
+				{Name: "test", Type: "run", Status: codeship.StepStatusSuccess},
+				{Name: "integration tests", Type: "run", Status: codeship.StepStatusError},
+			},
+		},
+		{
+			name: "missing and unexpected",
+			actual: []codeship.BuildStep{
+				{Name: "test", Type: "run", Status: codeship.StepStatusSuccess},
+				{Name: "integration tests", Type: "run", Status: codeship.StepStatusSkipped},
+				{Name: "verify release", Type: "run", Status: codeship.StepStatusSuccess},
+			},
+			missing:    []string{"1.13.15 test", "1.14.10 test", "integration tests"},
+			unexpected: []string{"verify release"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := config.Compare(predicted, tt.actual)
+
+			var missing, unexpected []string
+			for _, step := range result.Missing {
+				missing = append(missing, step.Name)
+			}
+			for _, step := range result.Unexpected {
+				unexpected = append(unexpected, step.Name)
+			}
+
+			assert.Equal(t, tt.missing, missing)
+			assert.Equal(t, tt.unexpected, unexpected)
+			assert.Equal(t, len(tt.missing) == 0 && len(tt.unexpected) == 0, result.Matches())
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package config
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Services is a parsed codeship-services.yml file
+type Services struct {
+	File string
+	// Services in the order they are defined in the file
+	Services []Service
+}
+
+// Service is a single service defined in codeship-services.yml
+type Service struct {
+	Name             string
+	Image            string
+	Build            *Build
+	Command          string
+	Links            []string
+	DependsOn        []string
+	Volumes          []string
+	VolumesFrom      []string
+	Environment      map[string]string
+	EnvFile          []string
+	EncryptedEnvFile []string
+	Cached           bool
+	Pos              Position
+}
+
+// Build describes how the image of a service is built
+type Build struct {
+	Context           string
+	Dockerfile        string
+	Image             string
+	Args              map[string]string
+	EncryptedArgsFile string
+}
+
+// Get returns the service with the given name
+func (s *Services) Get(name string) (Service, bool) {
+	for _, svc := range s.Services {
+		if svc.Name == name {
+			return svc, true
+		}
+	}
+	return Service{}, false
+}
+
+type rawService struct {
+	Image            string      `yaml:"image"`
+	Build            rawBuild    `yaml:"build"`
+	Command          string      `yaml:"command"`
+	Links            []string    `yaml:"links"`
+	DependsOn        []string    `yaml:"depends_on"`
+	Volumes          []string    `yaml:"volumes"`
+	VolumesFrom      []string    `yaml:"volumes_from"`
+	Environment      environment `yaml:"environment"`
+	EnvFile          stringList  `yaml:"env_file"`
+	EncryptedEnvFile stringList  `yaml:"encrypted_env_file"`
+	Cached           bool        `yaml:"cached"`
+}
+
+// rawBuild is either a build context path or a mapping
+type rawBuild struct {
+	*Build
+}
+
+func (b *rawBuild) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		b.Build = &Build{Context: n.Value}
+		return nil
+	}
+
+	var raw struct {
+		Context           string      `yaml:"context"`
+		Dockerfile        string      `yaml:"dockerfile"`
+		Image             string      `yaml:"image"`
+		Args              environment `yaml:"args"`
+		EncryptedArgsFile string      `yaml:"encrypted_args_file"`
+	}
+	if err := n.Decode(&raw); err != nil {
+		return err
+	}
+
+	b.Build = &Build{
+		Context:           raw.Context,
+		Dockerfile:        raw.Dockerfile,
+		Image:             raw.Image,
+		Args:              raw.Args,
+		EncryptedArgsFile: raw.EncryptedArgsFile,
+	}
+	return nil
+}
+
+// stringList is either a single string or a list of strings
+type stringList []string
+
+func (l *stringList) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*l = []string{n.Value}
+		return nil
+	}
+
+	var list []string
+	if err := n.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// environment is either a mapping or a list of KEY=value strings
+type environment map[string]string
+
+func (e *environment) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.MappingNode {
+		var m map[string]string
+		if err := n.Decode(&m); err != nil {
+			return err
+		}
+		*e = m
+		return nil
+	}
+
+	var list []string
+	if err := n.Decode(&list); err != nil {
+		return err
+	}
+
+	m := make(map[string]string, len(list))
+	for _, kv := range list {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 1 {
+			m[parts[0]] = ""
+			continue
+		}
+		m[parts[0]] = parts[1]
+	}
+	*e = m
+	return nil
+}
+
+// ParseServices parses the contents of a codeship-services.yml file. file is
+// used to report positions in errors.
+func ParseServices(file string, data []byte) (*Services, error) {
+	root, err := parseDocument(file, data)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Services{File: file}
+	if root == nil {
+		return s, nil
+	}
+
+	var errs Errors
+	if root.Kind != yaml.MappingNode {
+		errs.add(position(file, root), "services must be a mapping of service names to services")
+		return nil, errs
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+
+		if value.Kind != yaml.MappingNode {
+			errs.add(position(file, value), "service %q must be a mapping", key.Value)
+			continue
+		}
+
+		var raw rawService
+		if err := value.Decode(&raw); err != nil {
+			errs = append(errs, decodeErrors(file, value, err)...)
+			continue
+		}
+
+		s.Services = append(s.Services, Service{
+			Name:             key.Value,
+			Image:            raw.Image,
+			Build:            raw.Build.Build,
+			Command:          raw.Command,
+			Links:            raw.Links,
+			DependsOn:        raw.DependsOn,
+			Volumes:          raw.Volumes,
+			VolumesFrom:      raw.VolumesFrom,
+			Environment:      raw.Environment,
+			EnvFile:          raw.EnvFile,
+			EncryptedEnvFile: raw.EncryptedEnvFile,
+			Cached:           raw.Cached,
+			Pos:              position(file, key),
+		})
+	}
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Services) validate() Errors {
+	var errs Errors
+
+	seen := make(map[string]bool, len(s.Services))
+	for _, svc := range s.Services {
+		if seen[svc.Name] {
+			errs.add(svc.Pos, "service %q is defined more than once", svc.Name)
+		}
+		seen[svc.Name] = true
+	}
+
+	for _, svc := range s.Services {
+		if svc.Image == "" && svc.Build == nil {
+			errs.add(svc.Pos, "service %q must specify an image or build", svc.Name)
+		}
+
+		refs := map[string][]string{
+			"links":        svc.Links,
+			"depends_on":   svc.DependsOn,
+			"volumes_from": svc.VolumesFrom,
+		}
+		fields := make([]string, 0, len(refs))
+		for field := range refs {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			for _, ref := range refs[field] {
+				// links may be aliased as service:alias
+				name := strings.SplitN(ref, ":", 2)[0]
+				switch {
+				case name == svc.Name:
+					errs.add(svc.Pos, "service %q %s refers to itself", svc.Name, field)
+				case !seen[name]:
+					errs.add(svc.Pos, "service %q %s refers to undefined service %q", svc.Name, field, name)
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// parseDocument parses data into the root node of its document, returning nil
+// for an empty document
+func parseDocument(file string, data []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse %s", file)
+	}
+
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	return doc.Content[0], nil
+}
+
+var _lineRegexp = regexp.MustCompile(`^line (\d+): `)
+
+// decodeErrors converts an error returned by decoding n to Errors, using the
+// line reported by yaml where available
+func decodeErrors(file string, n *yaml.Node, err error) Errors {
+	te, ok := err.(*yaml.TypeError)
+	if !ok {
+		return Errors{{Pos: position(file, n), Msg: err.Error()}}
+	}
+
+	errs := make(Errors, 0, len(te.Errors))
+	for _, msg := range te.Errors {
+		pos := position(file, n)
+		if m := _lineRegexp.FindStringSubmatch(msg); m != nil {
+			pos = Position{File: file}
+			pos.Line, _ = strconv.Atoi(m[1])
+			msg = msg[len(m[0]):]
+		}
+		errs = append(errs, Error{Pos: pos, Msg: msg})
+	}
+	return errs
+}
@@ -0,0 +1,162 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/codeship/codeship-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServices(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		services []config.Service
+		err      string
+	}{
+		{
+			name: "full service",
+			data: `
+app:
+  build:
+    image: org/app
+    dockerfile: Dockerfile.test
+    args:
+      GO_VERSION: "1.15"
+  command: ./bin/server
+  links:
+    - postgres
+  depends_on: [postgres]
+  volumes:
+    - ./tmp:/tmp
+  environment:
+    - ENV=test
+    - DEBUG
+  env_file: test.env
+  encrypted_env_file: [one.env.encrypted, two.env.encrypted]
+  cached: true
+postgres:
+  image: postgres:9.6
+  environment:
+    POSTGRES_PASSWORD: secret
+`,
+			services: []config.Service{
+				{
+					Name: "app",
+					Build: &config.Build{
+						Image:      "org/app",
+						Dockerfile: "Dockerfile.test",
+						Args:       map[string]string{"GO_VERSION": "1.15"},
+					},
+					Command:          "./bin/server",
+					Links:            []string{"postgres"},
+					DependsOn:        []string{"postgres"},
+					Volumes:          []string{"./tmp:/tmp"},
+					Environment:      map[string]string{"ENV": "test", "DEBUG": ""},
+					EnvFile:          []string{"test.env"},
+					EncryptedEnvFile: []string{"one.env.encrypted", "two.env.encrypted"},
+					Cached:           true,
+					Pos:              config.Position{File: "codeship-services.yml", Line: 2, Column: 1},
+				},
+				{
+					Name:        "postgres",
+					Image:       "postgres:9.6",
+					Environment: map[string]string{"POSTGRES_PASSWORD": "secret"},
+					Pos:         config.Position{File: "codeship-services.yml", Line: 20, Column: 1},
+				},
+			},
+		},
+		{
+			name: "empty",
+			data: "",
+		},
+		{
+			name: "invalid yaml",
+			data: "app:\n  image: [",
+			err:  "unable to parse codeship-services.yml: yaml: line 2",
+		},
+		{
+			name: "not a mapping",
+			data: "- app\n",
+			err:  "codeship-services.yml:1:1: services must be a mapping of service names to services",
+		},
+		{
+			name: "service not a mapping",
+			data: "app: postgres\n",
+			err:  `codeship-services.yml:1:6: service "app" must be a mapping`,
+		},
+		{
+			name: "invalid field type",
+			data: "app:\n  image: postgres\n  links:\n    nested: value\n",
+			err:  "codeship-services.yml:4: cannot unmarshal !!map into []string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := config.ParseServices("codeship-services.yml", []byte(tt.data))
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.services, s.Services)
+		})
+	}
+}
+
+func TestServices_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		errs []string
+	}{
+		{
+			name: "valid",
+			data: "app:\n  build: .\n  links: [db:postgres]\ndb:\n  image: postgres\n",
+		},
+		{
+			name: "missing image and build",
+			data: "app:\n  command: ./bin/server\n",
+			errs: []string{`codeship-services.yml:1:1: service "app" must specify an image or build`},
+		},
+		{
+			name: "undefined references",
+			data: "app:\n  image: app\n  depends_on: [db]\n  volumes_from: [app]\n",
+			errs: []string{
+				`codeship-services.yml:1:1: service "app" depends_on refers to undefined service "db"`,
+				`codeship-services.yml:1:1: service "app" volumes_from refers to itself`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := config.ParseServices("codeship-services.yml", []byte(tt.data))
+			require.NoError(t, err)
+
+			c := &config.Config{Services: s, Steps: &config.Steps{}}
+			assertErrors(t, tt.errs, c.Validate())
+		})
+	}
+}
+
+func assertErrors(t *testing.T, want []string, err error) {
+	t.Helper()
+
+	if len(want) == 0 {
+		assert.NoError(t, err)
+		return
+	}
+
+	require.Error(t, err)
+	errs, ok := err.(config.Errors)
+	require.True(t, ok, "expected Errors, got %T", err)
+
+	got := make([]string, len(errs))
+	for i, e := range errs {
+		got[i] = e.Error()
+	}
+	assert.Equal(t, want, got)
+}
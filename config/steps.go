@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Step types supported in codeship-steps.yml. A step without a type runs a
+// command in a service.
+const (
+	StepTypeRun      = ""
+	StepTypeSerial   = "serial"
+	StepTypeParallel = "parallel"
+	StepTypePush     = "push"
+)
+
+// Steps is a parsed codeship-steps.yml file
+type Steps struct {
+	File  string
+	Steps []Step
+}
+
+// Step is a single step defined in codeship-steps.yml. Group steps of type
+// serial or parallel contain nested steps.
+type Step struct {
+	Name    string
+	Type    string
+	Service string
+	Command string
+	// Tag is a regular expression matched against the branch or tag being
+	// built. The step only runs if it matches.
+	Tag string
+	// Exclude is a regular expression matched against the branch or tag being
+	// built. The step is skipped if it matches.
+	Exclude string
+	// ImageName, ImageTag, Registry and EncryptedDockercfgPath are used by push steps
+	ImageName              string
+	ImageTag               string
+	Registry               string
+	EncryptedDockercfgPath string
+	Steps                  []Step
+	Pos                    Position
+}
+
+// IsGroup reports whether the step is a serial or parallel group of steps
+func (s Step) IsGroup() bool {
+	return s.Type == StepTypeSerial || s.Type == StepTypeParallel
+}
+
+type rawStep struct {
+	Name                   string      `yaml:"name"`
+	Type                   string      `yaml:"type"`
+	Service                string      `yaml:"service"`
+	Command                string      `yaml:"command"`
+	Tag                    string      `yaml:"tag"`
+	Exclude                string      `yaml:"exclude"`
+	ImageName              string      `yaml:"image_name"`
+	ImageTag               string      `yaml:"image_tag"`
+	Registry               string      `yaml:"registry"`
+	EncryptedDockercfgPath string      `yaml:"encrypted_dockercfg_path"`
+	Steps                  []yaml.Node `yaml:"steps"`
+}
+
+// ParseSteps parses the contents of a codeship-steps.yml file. file is used to
+// report positions in errors.
+func ParseSteps(file string, data []byte) (*Steps, error) {
+	root, err := parseDocument(file, data)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Steps{File: file}
+	if root == nil {
+		return s, nil
+	}
+
+	var errs Errors
+	if root.Kind != yaml.SequenceNode {
+		errs.add(position(file, root), "steps must be a list of steps")
+		return nil, errs
+	}
+
+	s.Steps = parseStepList(file, root.Content, &errs)
+
+	if err := errs.err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func parseStepList(file string, nodes []*yaml.Node, errs *Errors) []Step {
+	steps := make([]Step, 0, len(nodes))
+	for _, n := range nodes {
+		if step, ok := parseStep(file, n, errs); ok {
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+func parseStep(file string, n *yaml.Node, errs *Errors) (Step, bool) {
+	if n.Kind != yaml.MappingNode {
+		errs.add(position(file, n), "step must be a mapping")
+		return Step{}, false
+	}
+
+	var raw rawStep
+	if err := n.Decode(&raw); err != nil {
+		*errs = append(*errs, decodeErrors(file, n, err)...)
+		return Step{}, false
+	}
+
+	children := make([]*yaml.Node, len(raw.Steps))
+	for i := range raw.Steps {
+		children[i] = &raw.Steps[i]
+	}
+
+	return Step{
+		Name:                   raw.Name,
+		Type:                   raw.Type,
+		Service:                raw.Service,
+		Command:                raw.Command,
+		Tag:                    raw.Tag,
+		Exclude:                raw.Exclude,
+		ImageName:              raw.ImageName,
+		ImageTag:               raw.ImageTag,
+		Registry:               raw.Registry,
+		EncryptedDockercfgPath: raw.EncryptedDockercfgPath,
+		Steps:                  parseStepList(file, children, errs),
+		Pos:                    position(file, n),
+	}, true
+}
+
+func (s *Steps) validate(services *Services) Errors {
+	var errs Errors
+	for _, step := range s.Steps {
+		validateStep(step, "", services, &errs)
+	}
+	return errs
+}
+
+// validateStep validates step and its nested steps. service is the service
+// inherited from the enclosing group, if any.
+func validateStep(step Step, service string, services *Services, errs *Errors) {
+	name := "step"
+	if step.Name != "" {
+		name = fmt.Sprintf("step %q", step.Name)
+	}
+
+	if _, err := regexp.Compile(step.Tag); err != nil {
+		errs.add(step.Pos, "%s has an invalid tag: %v", name, err)
+	}
+	if _, err := regexp.Compile(step.Exclude); err != nil {
+		errs.add(step.Pos, "%s has an invalid exclude: %v", name, err)
+	}
+
+	if step.Service != "" {
+		service = step.Service
+		if _, ok := services.Get(step.Service); !ok {
+			errs.add(step.Pos, "%s refers to undefined service %q", name, step.Service)
+		}
+	}
+
+	switch step.Type {
+	case StepTypeSerial, StepTypeParallel:
+		if len(step.Steps) == 0 {
+			errs.add(step.Pos, "%s of type %s must have nested steps", name, step.Type)
+		}
+		if step.Command != "" {
+			errs.add(step.Pos, "%s of type %s cannot have a command", name, step.Type)
+		}
+		for _, child := range step.Steps {
+			validateStep(child, service, services, errs)
+		}
+		return
+	case StepTypeRun:
+		if len(step.Steps) > 0 {
+			errs.add(step.Pos, "%s has nested steps but no type, expected serial or parallel", name)
+		}
+	case StepTypePush:
+		if step.ImageName == "" {
+			errs.add(step.Pos, "%s of type push must have an image_name", name)
+		}
+	default:
+		errs.add(step.Pos, "%s has unsupported type %q", name, step.Type)
+		return
+	}
+
+	if service == "" {
+		errs.add(step.Pos, "%s must specify a service", name)
+	}
+}
@@ -0,0 +1,158 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/codeship/codeship-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSteps(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		steps []config.Step
+		err   string
+	}{
+		{
+			name: "nested steps",
+			data: `
+- type: serial
+  service: app
+  tag: ^master$
+  steps:
+  - name: test
+    command: ./scripts/test
+  - name: push
+    type: push
+    image_name: org/app
+    registry: https://index.docker.io/v1/
+    encrypted_dockercfg_path: dockercfg.encrypted
+- name: lint
+  service: app
+  exclude: ^docs/
+  command: ./scripts/lint
+`,
+			steps: []config.Step{
+				{
+					Type:    config.StepTypeSerial,
+					Service: "app",
+					Tag:     "^master$",
+					Steps: []config.Step{
+						{
+							Name:    "test",
+							Command: "./scripts/test",
+							Steps:   []config.Step{},
+							Pos:     config.Position{File: "codeship-steps.yml", Line: 6, Column: 5},
+						},
+						{
+							Name:                   "push",
+							Type:                   config.StepTypePush,
+							ImageName:              "org/app",
+							Registry:               "https://index.docker.io/v1/",
+							EncryptedDockercfgPath: "dockercfg.encrypted",
+							Steps:                  []config.Step{},
+							Pos:                    config.Position{File: "codeship-steps.yml", Line: 8, Column: 5},
+						},
+					},
+					Pos: config.Position{File: "codeship-steps.yml", Line: 2, Column: 3},
+				},
+				{
+					Name:    "lint",
+					Service: "app",
+					Exclude: "^docs/",
+					Command: "./scripts/lint",
+					Steps:   []config.Step{},
+					Pos:     config.Position{File: "codeship-steps.yml", Line: 13, Column: 3},
+				},
+			},
+		},
+		{
+			name: "not a list",
+			data: "name: test\n",
+			err:  "codeship-steps.yml:1:1: steps must be a list of steps",
+		},
+		{
+			name: "step not a mapping",
+			data: "- test\n",
+			err:  "codeship-steps.yml:1:3: step must be a mapping",
+		},
+		{
+			name: "nested step not a mapping",
+			data: "- type: serial\n  steps:\n  - test\n",
+			err:  "codeship-steps.yml:3:5: step must be a mapping",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := config.ParseSteps("codeship-steps.yml", []byte(tt.data))
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.steps, s.Steps)
+		})
+	}
+}
+
+func TestSteps_Validate(t *testing.T) {
+	services, err := config.ParseServices("codeship-services.yml", []byte("app:\n  image: app\n"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data string
+		errs []string
+	}{
+		{
+			name: "valid",
+			data: "- type: parallel\n  service: app\n  steps:\n  - command: a\n  - command: b\n",
+		},
+		{
+			name: "missing service",
+			data: "- name: test\n  command: ./scripts/test\n",
+			errs: []string{`codeship-steps.yml:1:3: step "test" must specify a service`},
+		},
+		{
+			name: "empty group with command",
+			data: "- type: serial\n  command: ./scripts/test\n",
+			errs: []string{
+				"codeship-steps.yml:1:3: step of type serial must have nested steps",
+				"codeship-steps.yml:1:3: step of type serial cannot have a command",
+			},
+		},
+		{
+			name: "nested steps without type",
+			data: "- service: app\n  steps:\n  - command: a\n",
+			errs: []string{"codeship-steps.yml:1:3: step has nested steps but no type, expected serial or parallel"},
+		},
+		{
+			name: "push without image name",
+			data: "- type: push\n  service: app\n",
+			errs: []string{"codeship-steps.yml:1:3: step of type push must have an image_name"},
+		},
+		{
+			name: "unsupported type",
+			data: "- type: manifest\n  service: app\n",
+			errs: []string{`codeship-steps.yml:1:3: step has unsupported type "manifest"`},
+		},
+		{
+			name: "invalid exclude",
+			data: "- service: app\n  exclude: \"[\"\n",
+			errs: []string{"codeship-steps.yml:1:3: step has an invalid exclude: error parsing regexp: missing closing ]: `[`"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := config.ParseSteps("codeship-steps.yml", []byte(tt.data))
+			require.NoError(t, err)
+
+			c := &config.Config{Services: services, Steps: s}
+			assertErrors(t, tt.errs, c.Validate())
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Package crypto encrypts and decrypts files, such as *.env.encrypted files,
+// in the format used by Codeship Pro, using a project's AES key.
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// header prefixes every encrypted file
+const header = "codeship:v2\n"
+
+// ErrInvalidFormat is returned when decrypting data that is not in the
+// Codeship encrypted file format
+var ErrInvalidFormat = errors.New("data is not in the codeship:v2 encrypted format")
+
+// Key is an AES key used to encrypt and decrypt files
+type Key []byte
+
+// ParseKey parses a base64 encoded key, as returned in Project.AesKey or
+// stored in a codeship.aes file
+func ParseKey(s string) (Key, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to decode AES key")
+	}
+
+	switch len(b) {
+	case 16, 24, 32:
+	default:
+		return nil, errors.Errorf("invalid AES key length %d", len(b))
+	}
+
+	return Key(b), nil
+}
+
+// ReadKeyFile reads a key from a file such as codeship.aes
+func ReadKeyFile(path string) (Key, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read AES key")
+	}
+	return ParseKey(string(b))
+}
+
+// String returns the base64 encoding of the key
+func (k Key) String() string {
+	return base64.StdEncoding.EncodeToString(k)
+}
+
+// Encrypt encrypts plaintext with AES-GCM and encodes it in the Codeship
+// encrypted file format
+func Encrypt(key Key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "unable to generate nonce")
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+
+	out := make([]byte, len(header)+base64.StdEncoding.EncodedLen(len(sealed)))
+	copy(out, header)
+	base64.StdEncoding.Encode(out[len(header):], sealed)
+	return out, nil
+}
+
+// Decrypt decrypts data in the Codeship encrypted file format
+func Decrypt(key Key, data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte(header)) {
+		return nil, ErrInvalidFormat
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data[len(header):])))
+	if err != nil {
+		return nil, ErrInvalidFormat
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, ErrInvalidFormat
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("unable to decrypt data, the AES key may be incorrect")
+	}
+
+	return plaintext, nil
+}
+
+// EncryptFile encrypts the file at src and writes it to dst
+func EncryptFile(key Key, src, dst string) error {
+	return transformFile(src, dst, func(b []byte) ([]byte, error) {
+		return Encrypt(key, b)
+	})
+}
+
+// DecryptFile decrypts the file at src and writes it to dst
+func DecryptFile(key Key, src, dst string) error {
+	return transformFile(src, dst, func(b []byte) ([]byte, error) {
+		return Decrypt(key, b)
+	})
+}
+
+func transformFile(src, dst string, fn func([]byte) ([]byte, error)) error {
+	in, err := ioutil.ReadFile(src)
+	if err != nil {
+		return errors.Wrap(err, "unable to read file")
+	}
+
+	out, err := fn(in)
+	if err != nil {
+		return errors.Wrapf(err, "unable to process %s", src)
+	}
+
+	mode := os.FileMode(0600)
+	if fi, err := os.Stat(dst); err == nil {
+		mode = fi.Mode().Perm()
+	}
+
+	if err := ioutil.WriteFile(dst, out, mode); err != nil {
+		return errors.Wrap(err, "unable to write file")
+	}
+	return nil
+}
+
+func newGCM(key Key) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid AES key")
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create cipher")
+	}
+	return gcm, nil
+}
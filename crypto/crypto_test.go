@@ -0,0 +1,163 @@
+package crypto_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/codeship/codeship-go/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	b, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return b
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		err  string
+	}{
+		{
+			name: "256 bit key",
+			key:  "MkGjQB02gEn5whGlXAhiieVcIzU22zXfG7AjahvuW9Y=\n",
+		},
+		{
+			name: "128 bit key",
+			key:  "AAECAwQFBgcICQoLDA0ODw==",
+		},
+		{
+			name: "not base64",
+			key:  "not a key",
+			err:  "unable to decode AES key",
+		},
+		{
+			name: "invalid length",
+			key:  "AAECAwQ=",
+			err:  "invalid AES key length 5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := crypto.ParseKey(tt.key)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, strings.TrimSpace(tt.key), key.String())
+		})
+	}
+}
+
+func TestDecrypt(t *testing.T) {
+	key, err := crypto.ReadKeyFile("testdata/codeship.aes")
+	require.NoError(t, err)
+
+	wrongKey, err := crypto.ParseKey("AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		key  crypto.Key
+		data []byte
+		err  string
+	}{
+		{
+			name: "fixture",
+			key:  key,
+			data: readFixture(t, "test.env.encrypted"),
+		},
+		{
+			name: "wrong key",
+			key:  wrongKey,
+			data: readFixture(t, "test.env.encrypted"),
+			err:  "unable to decrypt data, the AES key may be incorrect",
+		},
+		{
+			name: "missing header",
+			key:  key,
+			data: readFixture(t, "test.env"),
+			err:  crypto.ErrInvalidFormat.Error(),
+		},
+		{
+			name: "invalid base64",
+			key:  key,
+			data: []byte("codeship:v2\n!!!"),
+			err:  crypto.ErrInvalidFormat.Error(),
+		},
+		{
+			name: "truncated",
+			key:  key,
+			data: []byte("codeship:v2\nAAECAwQ="),
+			err:  crypto.ErrInvalidFormat.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plaintext, err := crypto.Decrypt(tt.key, tt.data)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, readFixture(t, "test.env"), plaintext)
+		})
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	key, err := crypto.ReadKeyFile("testdata/codeship.aes")
+	require.NoError(t, err)
+
+	plaintext := readFixture(t, "test.env")
+
+	a, err := crypto.Encrypt(key, plaintext)
+	require.NoError(t, err)
+	b, err := crypto.Encrypt(key, plaintext)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(string(a), "codeship:v2\n"))
+	assert.NotEqual(t, a, b, "expected a random nonce per encryption")
+
+	for _, data := range [][]byte{a, b} {
+		decrypted, err := crypto.Decrypt(key, data)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	}
+}
+
+func TestEncryptFile(t *testing.T) {
+	key, err := crypto.ReadKeyFile("testdata/codeship.aes")
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "codeship-crypto")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	encrypted := filepath.Join(dir, "test.env.encrypted")
+	decrypted := filepath.Join(dir, "test.env")
+
+	require.NoError(t, crypto.EncryptFile(key, "testdata/test.env", encrypted))
+	require.NoError(t, crypto.DecryptFile(key, encrypted, decrypted))
+
+	b, err := ioutil.ReadFile(decrypted)
+	require.NoError(t, err)
+	assert.Equal(t, readFixture(t, "test.env"), b)
+
+	fi, err := os.Stat(decrypted)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	err = crypto.DecryptFile(key, "testdata/test.env", decrypted)
+	assert.EqualError(t, err, "unable to process testdata/test.env: "+crypto.ErrInvalidFormat.Error())
+}
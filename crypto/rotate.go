@@ -0,0 +1,241 @@
+package crypto
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/internal/fileutil"
+	"github.com/pkg/errors"
+)
+
+// KeyFile is the name of the file the jet CLI reads a project's AES key from
+const KeyFile = "codeship.aes"
+
+// ProjectClient is the subset of *codeship.Organization used to rotate keys
+type ProjectClient interface {
+	GetProject(ctx context.Context, projectUUID string) (codeship.Project, codeship.Response, error)
+	ResetProjectAESKey(ctx context.Context, projectUUID string) (codeship.Project, codeship.Response, error)
+}
+
+var _ ProjectClient = &codeship.Organization{}
+
+// OldKeyFile is the name of the file RotateKey saves the previous AES key to
+// while rotating, and keeps if the rotation fails once the key may have been
+// reset. Like KeyFile, it must be listed in .gitignore.
+const OldKeyFile = KeyFile + ".old"
+
+// writeAttempts is the number of times RotateKey tries to write each file once
+// the key of the project has been reset
+const writeAttempts = 3
+
+// RotateResult describes the outcome of RotateKey
+type RotateResult struct {
+	// Files are the encrypted files that were re-encrypted, relative to the directory
+	Files []string
+	// Failed are the encrypted files that could not be re-encrypted, relative
+	// to the directory. They are still encrypted with OldKey.
+	Failed []string
+	// OldKey is the key of the project before it was reset
+	OldKey Key
+	// NewKey is set once the key of the project has been reset, even if
+	// re-encrypting the files failed afterwards
+	NewKey Key
+}
+
+type rotatedFile struct {
+	path      string
+	mode      os.FileMode
+	plaintext []byte
+	rotated   []byte
+}
+
+// RotateKey resets the AES key of a project and re-encrypts every *.encrypted
+// file under dir with the new key. A codeship.aes file in dir is updated with
+// the new key.
+//
+// All files are decrypted with the current key before the key is reset, so a
+// file that cannot be decrypted aborts the rotation without any changes. The
+// current key is saved to codeship.aes.old in dir before the key is reset, as
+// files cannot be decrypted with it once it is lost. Once the key is reset,
+// every file is written with the new key, retrying failed writes. If any file
+// still fails, the files that were written keep the new key, the files that
+// failed are listed in Failed and codeship.aes.old is kept so that they can
+// be re-encrypted later. Otherwise codeship.aes.old is removed.
+//
+// If resetting the key fails, the key may still have been reset by the API,
+// so codeship.aes.old is kept and the caller has to check the key of the
+// project and remove it once no file uses it anymore. codeship.aes.old holds
+// the key in plain text and must be listed in .gitignore, like codeship.aes.
+//
+// RotateKey refuses to run while codeship.aes.old exists, so that a key saved
+// by a previous rotation is never overwritten.
+func RotateKey(ctx context.Context, client ProjectClient, projectUUID, dir string) (RotateResult, error) {
+	var result RotateResult
+
+	project, _, err := client.GetProject(ctx, projectUUID)
+	if err != nil {
+		return result, errors.Wrap(err, "unable to rotate AES key")
+	}
+
+	oldKey, err := ParseKey(project.AesKey)
+	if err != nil {
+		return result, errors.Wrap(err, "unable to rotate AES key")
+	}
+	result.OldKey = oldKey
+
+	files, err := readEncryptedFiles(dir, oldKey)
+	if err != nil {
+		return result, errors.Wrap(err, "unable to rotate AES key")
+	}
+
+	keyFile, err := readKeyFile(dir)
+	if err != nil {
+		return result, errors.Wrap(err, "unable to rotate AES key")
+	}
+
+	oldKeyPath := filepath.Join(dir, OldKeyFile)
+	if _, err := os.Stat(oldKeyPath); err == nil {
+		return result, errors.Errorf("unable to rotate AES key: %s exists, re-encrypt the files still using it and remove it first", oldKeyPath)
+	} else if !os.IsNotExist(err) {
+		return result, errors.Wrap(err, "unable to rotate AES key")
+	}
+
+	if err := fileutil.WriteFileAtomic(oldKeyPath, []byte(oldKey.String()+"\n"), 0600); err != nil {
+		return result, errors.Wrap(err, "unable to rotate AES key: unable to save the current key")
+	}
+
+	// From here on the key of the project may have changed, even if the reset
+	// fails, so the previous key is kept on every error
+	project, _, err = client.ResetProjectAESKey(ctx, projectUUID)
+	if err != nil {
+		return result, errors.Wrapf(err, "unable to rotate AES key: the key may have been reset, the previous key was saved to %s, check the key of the project and remove it once no file uses it", oldKeyPath)
+	}
+
+	newKey, err := ParseKey(project.AesKey)
+	if err != nil {
+		return result, errors.Wrapf(err, "unable to rotate AES key: project was reset with an invalid key, the previous key was saved to %s", oldKeyPath)
+	}
+	result.NewKey = newKey
+
+	if keyFile != nil {
+		keyFile.rotated = []byte(newKey.String() + "\n")
+	}
+
+	// The old key is no longer valid for the project, so roll forward and
+	// write every file with the new key rather than restoring them
+	var failed []rotatedFile
+	var lastErr error
+	for _, f := range files {
+		if f.rotated, err = Encrypt(newKey, f.plaintext); err != nil {
+			failed = append(failed, f)
+			lastErr = err
+			continue
+		}
+		if err := writeRetry(f); err != nil {
+			failed = append(failed, f)
+			lastErr = err
+			continue
+		}
+		result.Files = append(result.Files, relPath(dir, f.path))
+	}
+
+	for _, f := range failed {
+		result.Failed = append(result.Failed, relPath(dir, f.path))
+	}
+
+	if keyFile != nil {
+		if err := writeRetry(*keyFile); err != nil {
+			return result, errors.Wrapf(err, "unable to rotate AES key: AES key was reset but %s was not updated, the previous key was saved to %s", keyFile.path, oldKeyPath)
+		}
+	}
+
+	if len(failed) > 0 {
+		return result, errors.Wrapf(lastErr, "unable to rotate AES key: AES key was reset but not all files were re-encrypted, the previous key was saved to %s to decrypt %s", oldKeyPath, strings.Join(result.Failed, ", "))
+	}
+
+	_ = os.Remove(oldKeyPath)
+
+	return result, nil
+}
+
+// writeRetry writes the rotated contents of a file, retrying failed writes
+func writeRetry(f rotatedFile) error {
+	var err error
+	for i := 0; i < writeAttempts; i++ {
+		if err = fileutil.WriteFileAtomic(f.path, f.rotated, f.mode); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func relPath(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// readEncryptedFiles reads and decrypts every *.encrypted file under dir
+func readEncryptedFiles(dir string, key Key) ([]rotatedFile, error) {
+	var files []rotatedFile
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if path != dir && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(info.Name(), ".encrypted") || !info.Mode().IsRegular() {
+			return nil
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		plaintext, err := Decrypt(key, b)
+		if err != nil {
+			return errors.Wrapf(err, "unable to decrypt %s", path)
+		}
+
+		files = append(files, rotatedFile{
+			path:      path,
+			mode:      info.Mode().Perm(),
+			plaintext: plaintext,
+		})
+		return nil
+	})
+
+	return files, err
+}
+
+// readKeyFile stats the codeship.aes file in dir, returning nil if there is none
+func readKeyFile(dir string) (*rotatedFile, error) {
+	path := filepath.Join(dir, KeyFile)
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &rotatedFile{
+		path: path,
+		mode: info.Mode().Perm(),
+	}, nil
+}
@@ -0,0 +1,222 @@
+package crypto_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const newKey = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+
+type projectClient struct {
+	key      string
+	getErr   error
+	resetErr error
+	onReset  func()
+	resets   int
+	// resetKey changes the key before resetErr is returned, like a reset
+	// that succeeded but whose response was lost
+	resetKey bool
+}
+
+func (c *projectClient) GetProject(ctx context.Context, projectUUID string) (codeship.Project, codeship.Response, error) {
+	return codeship.Project{UUID: projectUUID, AesKey: c.key}, codeship.Response{}, c.getErr
+}
+
+func (c *projectClient) ResetProjectAESKey(ctx context.Context, projectUUID string) (codeship.Project, codeship.Response, error) {
+	c.resets++
+	if c.resetKey {
+		c.key = newKey
+	}
+	if c.resetErr != nil {
+		return codeship.Project{}, codeship.Response{}, c.resetErr
+	}
+	if c.onReset != nil {
+		c.onReset()
+	}
+	c.key = newKey
+	return codeship.Project{UUID: projectUUID, AesKey: c.key}, codeship.Response{}, nil
+}
+
+// repo creates a directory with encrypted files and a codeship.aes file
+func repo(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "codeship-rotate")
+	require.NoError(t, err)
+
+	encrypted := readFixture(t, "test.env.encrypted")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "workers"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "test.env.encrypted"), encrypted, 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "workers", "prod.env.encrypted"), encrypted, 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, ".git", "ignored.encrypted"), []byte("not encrypted"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "codeship.aes"), readFixture(t, "codeship.aes"), 0600))
+
+	return dir
+}
+
+func TestRotateKey(t *testing.T) {
+	dir := repo(t)
+	defer os.RemoveAll(dir)
+
+	client := &projectClient{key: string(readFixture(t, "codeship.aes"))}
+
+	result, err := crypto.RotateKey(context.Background(), client, "0059df30-7701-0135-8810-6e5f001a2e3c", dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"test.env.encrypted", filepath.Join("workers", "prod.env.encrypted")}, result.Files)
+	assert.Equal(t, newKey, result.NewKey.String())
+
+	key, err := crypto.ReadKeyFile(filepath.Join(dir, "codeship.aes"))
+	require.NoError(t, err)
+	assert.Equal(t, result.NewKey, key)
+
+	for _, name := range result.Files {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+
+		plaintext, err := crypto.Decrypt(key, b)
+		require.NoError(t, err)
+		assert.Equal(t, readFixture(t, "test.env"), plaintext)
+	}
+
+	fi, err := os.Stat(filepath.Join(dir, "workers", "prod.env.encrypted"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	_, err = os.Stat(filepath.Join(dir, crypto.OldKeyFile))
+	assert.True(t, os.IsNotExist(err), "expected previous key to be removed")
+}
+
+func TestRotateKey_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *projectClient
+		setup  func(dir string)
+		resets int
+		keep   bool
+		err    string
+	}{
+		{
+			name:   "get project fails",
+			client: &projectClient{getErr: errors.New("boom")},
+			err:    "unable to rotate AES key: boom",
+		},
+		{
+			name:   "file encrypted with another key",
+			client: &projectClient{key: newKey},
+			err:    "unable to decrypt",
+		},
+		{
+			name:   "reset fails",
+			client: &projectClient{resetErr: errors.New("boom")},
+			resets: 1,
+			keep:   true,
+			err:    "check the key of the project and remove it once no file uses it: boom",
+		},
+		{
+			name:   "reset fails after changing the key",
+			client: &projectClient{resetErr: errors.New("timeout"), resetKey: true},
+			resets: 1,
+			keep:   true,
+			err:    "the key may have been reset",
+		},
+		{
+			name:   "previous key was kept",
+			client: &projectClient{},
+			setup: func(dir string) {
+				_ = ioutil.WriteFile(filepath.Join(dir, crypto.OldKeyFile), []byte(newKey), 0600)
+			},
+			err: "codeship.aes.old exists",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := repo(t)
+			defer os.RemoveAll(dir)
+
+			if tt.client.key == "" {
+				tt.client.key = string(readFixture(t, "codeship.aes"))
+			}
+			if tt.setup != nil {
+				tt.setup(dir)
+			}
+
+			result, err := crypto.RotateKey(context.Background(), tt.client, "0059df30-7701-0135-8810-6e5f001a2e3c", dir)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			assert.Equal(t, tt.resets, tt.client.resets)
+			assert.Nil(t, result.NewKey)
+
+			b, err := ioutil.ReadFile(filepath.Join(dir, "test.env.encrypted"))
+			require.NoError(t, err)
+			assert.Equal(t, readFixture(t, "test.env.encrypted"), b, "expected file to be unchanged")
+
+			b, err = ioutil.ReadFile(filepath.Join(dir, "codeship.aes"))
+			require.NoError(t, err)
+			assert.Equal(t, readFixture(t, "codeship.aes"), b, "expected key file to be unchanged")
+
+			switch {
+			case tt.keep:
+				key, err := crypto.ReadKeyFile(filepath.Join(dir, crypto.OldKeyFile))
+				require.NoError(t, err)
+				assert.Equal(t, string(readFixture(t, "codeship.aes")), key.String()+"\n", "expected previous key to be kept")
+			case tt.setup == nil:
+				_, err = os.Stat(filepath.Join(dir, crypto.OldKeyFile))
+				assert.True(t, os.IsNotExist(err), "expected previous key to be removed")
+			}
+		})
+	}
+}
+
+func TestRotateKey_WriteFails(t *testing.T) {
+	dir := repo(t)
+	defer os.RemoveAll(dir)
+
+	moved := filepath.Join(dir, "moved")
+	client := &projectClient{
+		key: string(readFixture(t, "codeship.aes")),
+		onReset: func() {
+			// replacing the directory with a file makes writing the nested
+			// file fail after test.env.encrypted was written
+			require.NoError(t, os.Rename(filepath.Join(dir, "workers"), moved))
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "workers"), nil, 0644))
+		},
+	}
+
+	result, err := crypto.RotateKey(context.Background(), client, "0059df30-7701-0135-8810-6e5f001a2e3c", dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AES key was reset but not all files were re-encrypted")
+	assert.Equal(t, []string{"test.env.encrypted"}, result.Files)
+	assert.Equal(t, []string{filepath.Join("workers", "prod.env.encrypted")}, result.Failed)
+	assert.Equal(t, newKey, result.NewKey.String())
+
+	// The files that were written and the key file use the new key
+	key, err := crypto.ReadKeyFile(filepath.Join(dir, "codeship.aes"))
+	require.NoError(t, err)
+	assert.Equal(t, result.NewKey, key)
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "test.env.encrypted"))
+	require.NoError(t, err)
+	plaintext, err := crypto.Decrypt(key, b)
+	require.NoError(t, err)
+	assert.Equal(t, readFixture(t, "test.env"), plaintext)
+
+	// The file that failed can still be decrypted with the previous key
+	oldKey, err := crypto.ReadKeyFile(filepath.Join(dir, crypto.OldKeyFile))
+	require.NoError(t, err)
+	assert.Equal(t, result.OldKey, oldKey)
+
+	b, err = ioutil.ReadFile(filepath.Join(moved, "prod.env.encrypted"))
+	require.NoError(t, err)
+	plaintext, err = crypto.Decrypt(oldKey, b)
+	require.NoError(t, err)
+	assert.Equal(t, readFixture(t, "test.env"), plaintext)
+}
@@ -0,0 +1,308 @@
+package codeship
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeType is the kind of a Change
+type ChangeType int
+
+const (
+	// ChangeAdded is a value that is only present in the second project
+	ChangeAdded ChangeType = iota
+	// ChangeRemoved is a value that is only present in the first project
+	ChangeRemoved
+	// ChangeModified is a value that differs between the projects
+	ChangeModified
+)
+
+var _changeTypeValueToName = map[ChangeType]string{
+	ChangeAdded:    "added",
+	ChangeRemoved:  "removed",
+	ChangeModified: "modified",
+}
+
+func (t ChangeType) String() string {
+	return _changeTypeValueToName[t]
+}
+
+// Change is a single difference between two projects. Old and New are
+// formatted for display, with secrets replaced by [REDACTED].
+type Change struct {
+	Type ChangeType
+	// Field is the JSON name of the project field, e.g. environment_variables
+	Field string
+	// Key identifies the element of a list field that changed, e.g. the name
+	// of an environment variable
+	Key string
+	Old string
+	New string
+}
+
+func (c Change) String() string {
+	name := c.Field
+	if c.Key != "" {
+		name += "[" + c.Key + "]"
+	}
+
+	switch c.Type {
+	case ChangeAdded:
+		return "+ " + name + ": " + c.New
+	case ChangeRemoved:
+		return "- " + name + ": " + c.Old
+	}
+	return "~ " + name + ": " + c.Old + " -> " + c.New
+}
+
+// DiffProjects returns the changes from project a to project b. Fields that
+// identify a project or change on every update, such as UUID and UpdatedAt,
+// are ignored. Secrets, such as the AES and SSH keys, environment variable
+// values and notification options, are never included in the changes.
+//
+// Changes are returned in a stable order: by field, then removals before
+// additions and modifications.
+func DiffProjects(a, b Project) []Change {
+	var changes []Change
+
+	diffString := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, Change{Type: ChangeModified, Field: field, Old: strconv.Quote(old), New: strconv.Quote(new)})
+		}
+	}
+	diffSecret := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, Change{Type: ChangeModified, Field: field, Old: redacted, New: redacted})
+		}
+	}
+
+	diffString("name", a.Name, b.Name)
+	diffString("repository_url", a.RepositoryURL, b.RepositoryURL)
+	diffString("repository_provider", a.RepositoryProvider, b.RepositoryProvider)
+	diffString("authentication_user", a.AuthenticationUser, b.AuthenticationUser)
+	if a.Type != b.Type {
+		changes = append(changes, Change{Type: ChangeModified, Field: "type", Old: a.Type.String(), New: b.Type.String()})
+	}
+	diffSecret("aes_key", a.AesKey, b.AesKey)
+	diffSecret("ssh_key", a.SSHKey, b.SSHKey)
+
+	changes = append(changes, diffTeamIDs(a.TeamIDs, b.TeamIDs)...)
+
+	if !equalStrings(a.SetupCommands, b.SetupCommands) {
+		changes = append(changes, Change{
+			Type:  ChangeModified,
+			Field: "setup_commands",
+			Old:   formatList(a.SetupCommands),
+			New:   formatList(b.SetupCommands),
+		})
+	}
+
+	changes = append(changes, diffEnvironmentVariables(a.EnvironmentVariables, b.EnvironmentVariables)...)
+	changes = append(changes, diffNotificationRules(a.NotificationRules, b.NotificationRules)...)
+	changes = append(changes, diffTestPipelines(a.TestPipelines, b.TestPipelines)...)
+	changes = append(changes, diffDeploymentPipelines(a.DeploymentPipelines, b.DeploymentPipelines)...)
+
+	return changes
+}
+
+func diffTeamIDs(a, b []int) []Change {
+	in := func(ids []int) map[int]bool {
+		m := make(map[int]bool, len(ids))
+		for _, id := range ids {
+			m[id] = true
+		}
+		return m
+	}
+	inA, inB := in(a), in(b)
+
+	var changes []Change
+	for _, id := range sortedInts(a) {
+		if !inB[id] {
+			changes = append(changes, Change{Type: ChangeRemoved, Field: "team_ids", Old: strconv.Itoa(id)})
+		}
+	}
+	for _, id := range sortedInts(b) {
+		if !inA[id] {
+			changes = append(changes, Change{Type: ChangeAdded, Field: "team_ids", New: strconv.Itoa(id)})
+		}
+	}
+	return changes
+}
+
+func diffEnvironmentVariables(a, b []EnvironmentVariable) []Change {
+	values := func(envs []EnvironmentVariable) map[string]string {
+		m := make(map[string]string, len(envs))
+		for _, env := range envs {
+			m[env.Name] = env.Value
+		}
+		return m
+	}
+	inA, inB := values(a), values(b)
+
+	var changes []Change
+	for _, env := range a {
+		if _, ok := inB[env.Name]; !ok {
+			changes = append(changes, Change{Type: ChangeRemoved, Field: "environment_variables", Key: env.Name, Old: redacted})
+		}
+	}
+	for _, env := range b {
+		value, ok := inA[env.Name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Type: ChangeAdded, Field: "environment_variables", Key: env.Name, New: redacted})
+		case value != env.Value:
+			changes = append(changes, Change{Type: ChangeModified, Field: "environment_variables", Key: env.Name, Old: redacted, New: redacted})
+		}
+	}
+	return changes
+}
+
+// diffNotificationRules compares rules as multisets, so reordering rules is
+// not a change
+func diffNotificationRules(a, b []NotificationRule) []Change {
+	var changes []Change
+	for _, rule := range subtractRules(a, b) {
+		changes = append(changes, Change{Type: ChangeRemoved, Field: "notification_rules", Key: rule.describe(), Old: rule.describe()})
+	}
+	for _, rule := range subtractRules(b, a) {
+		changes = append(changes, Change{Type: ChangeAdded, Field: "notification_rules", Key: rule.describe(), New: rule.describe()})
+	}
+	return changes
+}
+
+// subtractRules returns the rules in a that are not in b
+func subtractRules(a, b []NotificationRule) []NotificationRule {
+	remaining := append([]NotificationRule(nil), b...)
+
+	var diff []NotificationRule
+	for _, rule := range a {
+		found := false
+		for i, other := range remaining {
+			if rule.equal(other) {
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			diff = append(diff, rule)
+		}
+	}
+	return diff
+}
+
+// describe describes a notification rule without its options, which may
+// contain secrets such as webhook URLs and API keys
+func (r NotificationRule) describe() string {
+	statuses := make([]string, len(r.BuildStatuses))
+	for i, status := range r.BuildStatuses {
+		statuses[i] = string(status)
+	}
+	s := string(r.Notifier) + " on " + strings.Join(statuses, ",")
+	if r.Branch != "" {
+		s += " for branch " + r.Branch
+		if r.BranchMatch != "" && r.BranchMatch != BranchMatchExact {
+			s += " (" + r.BranchMatch + ")"
+		}
+	}
+	if r.Options.Room != "" {
+		s += " in " + r.Options.Room
+	}
+	if r.Target != "" && r.Target != NotificationTargetAll {
+		s += " to " + r.Target
+	}
+	return s
+}
+
+// diffTestPipelines compares pipelines by name, ignoring their IDs
+func diffTestPipelines(a, b []TestPipeline) []Change {
+	commands := func(pipelines []TestPipeline) map[string][]string {
+		m := make(map[string][]string, len(pipelines))
+		for _, p := range pipelines {
+			m[p.Name] = p.Commands
+		}
+		return m
+	}
+	inA, inB := commands(a), commands(b)
+
+	var changes []Change
+	for _, p := range a {
+		if _, ok := inB[p.Name]; !ok {
+			changes = append(changes, Change{Type: ChangeRemoved, Field: "test_pipelines", Key: p.Name, Old: formatList(p.Commands)})
+		}
+	}
+	for _, p := range b {
+		old, ok := inA[p.Name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Type: ChangeAdded, Field: "test_pipelines", Key: p.Name, New: formatList(p.Commands)})
+		case !equalStrings(old, p.Commands):
+			changes = append(changes, Change{Type: ChangeModified, Field: "test_pipelines", Key: p.Name, Old: formatList(old), New: formatList(p.Commands)})
+		}
+	}
+	return changes
+}
+
+// diffDeploymentPipelines compares pipelines by branch, ignoring their IDs.
+// Pipeline configs may contain credentials and are always redacted.
+func diffDeploymentPipelines(a, b []DeploymentPipeline) []Change {
+	key := func(p DeploymentPipeline) string {
+		if p.Branch.MatchMode == "" || p.Branch.MatchMode == BranchMatchExact {
+			return p.Branch.BranchName
+		}
+		return p.Branch.BranchName + " (" + p.Branch.MatchMode + ")"
+	}
+	pipelines := func(list []DeploymentPipeline) map[string]DeploymentPipeline {
+		m := make(map[string]DeploymentPipeline, len(list))
+		for _, p := range list {
+			m[key(p)] = p
+		}
+		return m
+	}
+	inA, inB := pipelines(a), pipelines(b)
+
+	var changes []Change
+	for _, p := range a {
+		if _, ok := inB[key(p)]; !ok {
+			changes = append(changes, Change{Type: ChangeRemoved, Field: "deployment_pipelines", Key: key(p), Old: redacted})
+		}
+	}
+	for _, p := range b {
+		old, ok := inA[key(p)]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Type: ChangeAdded, Field: "deployment_pipelines", Key: key(p), New: redacted})
+		case old.Position != p.Position || !reflect.DeepEqual(old.Config, p.Config):
+			changes = append(changes, Change{Type: ChangeModified, Field: "deployment_pipelines", Key: key(p), Old: redacted, New: redacted})
+		}
+	}
+	return changes
+}
+
+func formatList(list []string) string {
+	quoted := make([]string, len(list))
+	for i, s := range list {
+		quoted[i] = strconv.Quote(s)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedInts(ints []int) []int {
+	sorted := append([]int(nil), ints...)
+	sort.Ints(sorted)
+	return sorted
+}
@@ -0,0 +1,196 @@
+package codeship_test
+
+import (
+	"testing"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffProjects(t *testing.T) {
+	base := codeship.Project{
+		AesKey:             "aeskey",
+		AuthenticationUser: "Test User",
+		CreatedAt:          time.Date(2017, 9, 8, 20, 19, 55, 0, time.UTC),
+		ID:                 1,
+		Name:               "org/test-project",
+		NotificationRules: []codeship.NotificationRule{
+			codeship.GitHubNotification(codeship.NotifyOnFailed, codeship.NotifyOnSuccess),
+			codeship.SlackNotification("https://hooks.slack.com/services/T000/B000/XXX", codeship.NotifyOnFailed),
+		},
+		OrganizationUUID:   "28123f10-e33d-5533-b53f-111ef8d7b14f",
+		RepositoryProvider: "github",
+		RepositoryURL:      "https://github.com/org/test-project",
+		SetupCommands:      []string{"./setup"},
+		SSHKey:             "ssh-rsa key",
+		TeamIDs:            []int{1007, 61593},
+		EnvironmentVariables: []codeship.EnvironmentVariable{
+			{Name: "DATABASE_URL", Value: "postgres://db/test"},
+			{Name: "TOKEN", Value: "secret"},
+		},
+		TestPipelines: []codeship.TestPipeline{
+			{ID: 1, Name: "tests", Commands: []string{"make test"}},
+		},
+		DeploymentPipelines: []codeship.DeploymentPipeline{
+			{ID: 1, Branch: codeship.DeploymentBranch{BranchName: "master", MatchMode: "exact"}, Config: map[string]interface{}{"api_key": "secret"}},
+		},
+		Type:      codeship.ProjectTypeBasic,
+		UpdatedAt: time.Date(2017, 9, 13, 17, 13, 36, 0, time.UTC),
+		UUID:      "0059df30-7701-0135-8810-6e5f001a2e3c",
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(p *codeship.Project)
+		changes []string
+	}{
+		{
+			name:   "identical",
+			modify: func(p *codeship.Project) {},
+		},
+		{
+			name: "volatile and identifying fields are ignored",
+			modify: func(p *codeship.Project) {
+				p.ID = 2
+				p.UUID = "c38f3280-2d13-0134-d0b4-1e1b0ba06dd1"
+				p.OrganizationUUID = "other"
+				p.CreatedAt = time.Now()
+				p.UpdatedAt = time.Now()
+				p.TestPipelines = []codeship.TestPipeline{{ID: 5, Name: "tests", Commands: []string{"make test"}}}
+				p.DeploymentPipelines = []codeship.DeploymentPipeline{{ID: 7, Branch: codeship.DeploymentBranch{BranchName: "master"}, Config: map[string]interface{}{"api_key": "secret"}}}
+			},
+		},
+		{
+			name: "reordered rules and team ids",
+			modify: func(p *codeship.Project) {
+				p.NotificationRules = []codeship.NotificationRule{p.NotificationRules[1], p.NotificationRules[0]}
+				p.TeamIDs = []int{61593, 1007}
+			},
+		},
+		{
+			name: "reordered build statuses",
+			modify: func(p *codeship.Project) {
+				p.NotificationRules = []codeship.NotificationRule{
+					codeship.GitHubNotification(codeship.NotifyOnSuccess, codeship.NotifyOnFailed),
+					codeship.SlackNotification("https://hooks.slack.com/services/T000/B000/XXX", codeship.NotifyOnFailed),
+				}
+			},
+		},
+		{
+			name: "changed build statuses",
+			modify: func(p *codeship.Project) {
+				p.NotificationRules = []codeship.NotificationRule{
+					codeship.GitHubNotification(codeship.NotifyOnFailed, codeship.NotifyOnSuccess),
+					codeship.SlackNotification("https://hooks.slack.com/services/T000/B000/XXX", codeship.NotifyOnFailed, codeship.NotifyOnFailed, codeship.NotifyOnRecovered),
+				}
+			},
+			changes: []string{
+				"- notification_rules[slack on failed]: slack on failed",
+				"+ notification_rules[slack on failed,failed,recovered]: slack on failed,failed,recovered",
+			},
+		},
+		{
+			name: "scalar fields",
+			modify: func(p *codeship.Project) {
+				p.Name = "org/renamed"
+				p.Type = codeship.ProjectTypePro
+				p.AesKey = "newkey"
+				p.SSHKey = "ssh-rsa newkey"
+			},
+			changes: []string{
+				`~ name: "org/test-project" -> "org/renamed"`,
+				"~ type: basic -> pro",
+				"~ aes_key: [REDACTED] -> [REDACTED]",
+				"~ ssh_key: [REDACTED] -> [REDACTED]",
+			},
+		},
+		{
+			name: "lists",
+			modify: func(p *codeship.Project) {
+				p.TeamIDs = []int{1007, 70000}
+				p.SetupCommands = []string{"./setup", "./seed"}
+				p.EnvironmentVariables = []codeship.EnvironmentVariable{
+					{Name: "DATABASE_URL", Value: "postgres://db/other"},
+					{Name: "NEW", Value: "value"},
+				}
+			},
+			changes: []string{
+				"- team_ids: 61593",
+				"+ team_ids: 70000",
+				`~ setup_commands: ["./setup"] -> ["./setup", "./seed"]`,
+				"- environment_variables[TOKEN]: [REDACTED]",
+				"~ environment_variables[DATABASE_URL]: [REDACTED] -> [REDACTED]",
+				"+ environment_variables[NEW]: [REDACTED]",
+			},
+		},
+		{
+			name: "notification rules",
+			modify: func(p *codeship.Project) {
+				p.NotificationRules = []codeship.NotificationRule{
+					p.NotificationRules[0],
+					codeship.HipChatNotification("key", "devs", codeship.NotifyOnFailed).OnBranch("release/", codeship.BranchMatchPrefix),
+				}
+			},
+			changes: []string{
+				"- notification_rules[slack on failed]: slack on failed",
+				"+ notification_rules[hipchat on failed for branch release/ (prefix) in devs]: hipchat on failed for branch release/ (prefix) in devs",
+			},
+		},
+		{
+			name: "pipelines",
+			modify: func(p *codeship.Project) {
+				p.TestPipelines = []codeship.TestPipeline{
+					{ID: 1, Name: "tests", Commands: []string{"make test", "make lint"}},
+					{ID: 2, Name: "integration", Commands: []string{"make integration"}},
+				}
+				p.DeploymentPipelines = []codeship.DeploymentPipeline{
+					{ID: 1, Branch: codeship.DeploymentBranch{BranchName: "master", MatchMode: "exact"}, Config: map[string]interface{}{"api_key": "rotated"}},
+					{ID: 2, Branch: codeship.DeploymentBranch{BranchName: "release/", MatchMode: "prefix"}},
+				}
+			},
+			changes: []string{
+				`~ test_pipelines[tests]: ["make test"] -> ["make test", "make lint"]`,
+				`+ test_pipelines[integration]: ["make integration"]`,
+				"~ deployment_pipelines[master]: [REDACTED] -> [REDACTED]",
+				"+ deployment_pipelines[release/ (prefix)]: [REDACTED]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := base
+			tt.modify(&b)
+
+			var changes []string
+			for _, c := range codeship.DiffProjects(base, b) {
+				changes = append(changes, c.String())
+			}
+			assert.Equal(t, tt.changes, changes)
+
+			for _, c := range changes {
+				assert.NotContains(t, c, "secret")
+				assert.NotContains(t, c, "postgres://")
+				assert.NotContains(t, c, "hooks.slack.com")
+			}
+		})
+	}
+}
+
+func TestDiffProjects_Reverse(t *testing.T) {
+	a := codeship.Project{EnvironmentVariables: []codeship.EnvironmentVariable{{Name: "TOKEN", Value: "secret"}}}
+	b := codeship.Project{}
+
+	assert.Equal(t, []codeship.Change{
+		{Type: codeship.ChangeRemoved, Field: "environment_variables", Key: "TOKEN", Old: "[REDACTED]"},
+	}, codeship.DiffProjects(a, b))
+	assert.Equal(t, []codeship.Change{
+		{Type: codeship.ChangeAdded, Field: "environment_variables", Key: "TOKEN", New: "[REDACTED]"},
+	}, codeship.DiffProjects(b, a))
+}
+
+func TestChangeType_String(t *testing.T) {
+	assert.Equal(t, "added", codeship.ChangeAdded.String())
+	assert.Equal(t, "removed", codeship.ChangeRemoved.String())
+	assert.Equal(t, "modified", codeship.ChangeModified.String())
+}
@@ -0,0 +1,280 @@
+// Package export writes builds to CSV and JSON Lines files with a stable
+// column schema, including computed durations.
+//
+// Builds are streamed from an iterator, so memory use does not grow with the
+// number of builds exported:
+//
+//	it := org.IterateBuilds(ctx, projectUUID, codeship.PerPage(50))
+//	n, err := export.CSV(os.Stdout, it, export.Options{})
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/pkg/errors"
+)
+
+// Iterator yields the builds to export. *codeship.BuildIterator implements it.
+type Iterator interface {
+	Next() bool
+	Build() codeship.Build
+	Err() error
+}
+
+var _ Iterator = &codeship.BuildIterator{}
+
+// Field names that can be exported
+const (
+	FieldUUID                 = "uuid"
+	FieldOrganizationUUID     = "organization_uuid"
+	FieldProjectUUID          = "project_uuid"
+	FieldProjectID            = "project_id"
+	FieldBranch               = "branch"
+	FieldRef                  = "ref"
+	FieldCommitSha            = "commit_sha"
+	FieldCommitMessage        = "commit_message"
+	FieldUsername             = "username"
+	FieldStatus               = "status"
+	FieldQueuedAt             = "queued_at"
+	FieldAllocatedAt          = "allocated_at"
+	FieldFinishedAt           = "finished_at"
+	FieldQueueDurationSeconds = "queue_duration_seconds"
+	FieldRunDurationSeconds   = "run_duration_seconds"
+	FieldTotalDurationSeconds = "total_duration_seconds"
+)
+
+// DefaultFields are the fields exported when none are selected, in column order
+var DefaultFields = []string{
+	FieldUUID,
+	FieldOrganizationUUID,
+	FieldProjectUUID,
+	FieldProjectID,
+	FieldBranch,
+	FieldRef,
+	FieldCommitSha,
+	FieldCommitMessage,
+	FieldUsername,
+	FieldStatus,
+	FieldQueuedAt,
+	FieldAllocatedAt,
+	FieldFinishedAt,
+	FieldQueueDurationSeconds,
+	FieldRunDurationSeconds,
+	FieldTotalDurationSeconds,
+}
+
+// Options configures an export
+type Options struct {
+	// Fields selects and orders the exported columns. Defaults to DefaultFields.
+	Fields []string
+	// NoFormulaEscaping disables escaping CSV cells that spreadsheets would
+	// evaluate as formulas. By default, text cells starting with =, +, -, @,
+	// a tab or a carriage return are prefixed with a single quote.
+	NoFormulaEscaping bool
+}
+
+// field extracts a value from a build. A nil value is written as an empty CSV
+// cell or a JSON null.
+type field func(b codeship.Build) interface{}
+
+var fields = map[string]field{
+	FieldUUID:             func(b codeship.Build) interface{} { return b.UUID },
+	FieldOrganizationUUID: func(b codeship.Build) interface{} { return b.OrganizationUUID },
+	FieldProjectUUID:      func(b codeship.Build) interface{} { return b.ProjectUUID },
+	FieldProjectID:        func(b codeship.Build) interface{} { return b.ProjectID },
+	FieldBranch:           func(b codeship.Build) interface{} { return b.Branch },
+	FieldRef:              func(b codeship.Build) interface{} { return b.Ref },
+	FieldCommitSha:        func(b codeship.Build) interface{} { return b.CommitSha },
+	FieldCommitMessage:    func(b codeship.Build) interface{} { return b.CommitMessage },
+	FieldUsername:         func(b codeship.Build) interface{} { return b.Username },
+	FieldStatus:           func(b codeship.Build) interface{} { return b.Status },
+	FieldQueuedAt:         func(b codeship.Build) interface{} { return timestamp(b.QueuedAt) },
+	FieldAllocatedAt:      func(b codeship.Build) interface{} { return timestamp(b.AllocatedAt) },
+	FieldFinishedAt:       func(b codeship.Build) interface{} { return timestamp(b.FinishedAt) },
+	FieldQueueDurationSeconds: func(b codeship.Build) interface{} {
+		return seconds(b.QueuedAt, b.AllocatedAt)
+	},
+	FieldRunDurationSeconds: func(b codeship.Build) interface{} {
+		return seconds(b.AllocatedAt, b.FinishedAt)
+	},
+	FieldTotalDurationSeconds: func(b codeship.Build) interface{} {
+		return seconds(b.QueuedAt, b.FinishedAt)
+	},
+}
+
+func timestamp(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// seconds returns the number of seconds between two times, or nil if either is unknown
+func seconds(from, to time.Time) interface{} {
+	if from.IsZero() || to.IsZero() {
+		return nil
+	}
+	return to.Sub(from).Seconds()
+}
+
+func (o Options) columns() ([]string, []field, error) {
+	names := o.Fields
+	if len(names) == 0 {
+		names = DefaultFields
+	}
+
+	cols := make([]field, len(names))
+	for i, name := range names {
+		f, ok := fields[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown field %q", name)
+		}
+		cols[i] = f
+	}
+	return names, cols, nil
+}
+
+// CSV writes a header row followed by one row per build to w, returning the
+// number of builds written
+func CSV(w io.Writer, it Iterator, opts Options) (int, error) {
+	names, cols, err := opts.columns()
+	if err != nil {
+		return 0, err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(names); err != nil {
+		return 0, errors.Wrap(err, "unable to write CSV header")
+	}
+
+	n := 0
+	row := make([]string, len(cols))
+	for it.Next() {
+		b := it.Build()
+		for i, col := range cols {
+			row[i] = formatCSV(col(b), !opts.NoFormulaEscaping)
+		}
+		if err := cw.Write(row); err != nil {
+			return n, errors.Wrap(err, "unable to write CSV row")
+		}
+		n++
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return n, errors.Wrap(err, "unable to write CSV")
+	}
+
+	return n, errors.Wrap(it.Err(), "unable to iterate builds")
+}
+
+func formatCSV(v interface{}, escape bool) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		if escape {
+			return escapeFormula(t)
+		}
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// escapeFormula prefixes values that spreadsheets would evaluate as formulas,
+// e.g. a commit message starting with =, with a single quote so that they are
+// displayed as text
+func escapeFormula(s string) string {
+	if s == "" {
+		return s
+	}
+	switch s[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + s
+	}
+	return s
+}
+
+// JSONLines writes one JSON object per build to w, with keys in column order,
+// returning the number of builds written
+func JSONLines(w io.Writer, it Iterator, opts Options) (int, error) {
+	names, cols, err := opts.columns()
+	if err != nil {
+		return 0, err
+	}
+
+	keys := make([][]byte, len(names))
+	for i, name := range names {
+		keys[i], _ = json.Marshal(name)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	n := 0
+	var line bytes.Buffer
+	for it.Next() {
+		b := it.Build()
+
+		line.Reset()
+		line.WriteByte('{')
+		for i, col := range cols {
+			if i > 0 {
+				line.WriteByte(',')
+			}
+			value, err := json.Marshal(col(b))
+			if err != nil {
+				return n, errors.Wrap(err, "unable to encode JSON")
+			}
+			line.Write(keys[i])
+			line.WriteByte(':')
+			line.Write(value)
+		}
+		line.WriteString("}\n")
+
+		if _, err := bw.Write(line.Bytes()); err != nil {
+			return n, errors.Wrap(err, "unable to write JSON Lines")
+		}
+		n++
+	}
+
+	if err := bw.Flush(); err != nil {
+		return n, errors.Wrap(err, "unable to write JSON Lines")
+	}
+
+	return n, errors.Wrap(it.Err(), "unable to iterate builds")
+}
+
+// Slice returns an Iterator over a slice of builds, e.g. for exporting builds
+// loaded from a local store
+func Slice(builds []codeship.Build) Iterator {
+	return &sliceIterator{builds: builds, pos: -1}
+}
+
+type sliceIterator struct {
+	builds []codeship.Build
+	pos    int
+}
+
+func (s *sliceIterator) Next() bool {
+	s.pos++
+	return s.pos < len(s.builds)
+}
+
+func (s *sliceIterator) Build() codeship.Build {
+	return s.builds[s.pos]
+}
+
+func (s *sliceIterator) Err() error {
+	return nil
+}
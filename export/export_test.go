@@ -0,0 +1,184 @@
+package export_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/export"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var builds = []codeship.Build{
+	{
+		UUID:             "25a3dd8c-eb3e-4e75-1298-8cbcbe621342",
+		OrganizationUUID: "28123f10-e33d-5533-b53f-111ef8d7b14f",
+		ProjectUUID:      "0059df30-7701-0135-8810-6e5f001a2e3c",
+		ProjectID:        1,
+		Branch:           "master",
+		Ref:              "heads/master",
+		CommitSha:        "185ab4c7dc4eda2a027c284f7a669cac3f50a5ed",
+		CommitMessage:    "Fix \"quoted\", comma",
+		Username:         "fillup",
+		Status:           "success",
+		QueuedAt:         time.Date(2017, 9, 13, 17, 13, 0, 0, time.UTC),
+		AllocatedAt:      time.Date(2017, 9, 13, 17, 13, 30, 0, time.UTC),
+		FinishedAt:       time.Date(2017, 9, 13, 17, 15, 0, 500000000, time.UTC),
+	},
+	{
+		UUID:     "25a3dd8c-eb3e-4e75-1298-8cbcbe611111",
+		Status:   "testing",
+		QueuedAt: time.Date(2017, 9, 13, 18, 0, 0, 0, time.UTC),
+	},
+}
+
+// failingIterator yields its builds then reports an error
+type failingIterator struct {
+	export.Iterator
+}
+
+func (f failingIterator) Err() error {
+	return errors.New("boom")
+}
+
+func TestCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		opts export.Options
+		it   export.Iterator
+		want string
+		n    int
+		err  string
+	}{
+		{
+			name: "default fields",
+			it:   export.Slice(builds),
+			want: "uuid,organization_uuid,project_uuid,project_id,branch,ref,commit_sha,commit_message,username,status,queued_at,allocated_at,finished_at,queue_duration_seconds,run_duration_seconds,total_duration_seconds\n" +
+				"25a3dd8c-eb3e-4e75-1298-8cbcbe621342,28123f10-e33d-5533-b53f-111ef8d7b14f,0059df30-7701-0135-8810-6e5f001a2e3c,1,master,heads/master,185ab4c7dc4eda2a027c284f7a669cac3f50a5ed,\"Fix \"\"quoted\"\", comma\",fillup,success,2017-09-13T17:13:00Z,2017-09-13T17:13:30Z,2017-09-13T17:15:00Z,30,90.5,120.5\n" +
+				"25a3dd8c-eb3e-4e75-1298-8cbcbe611111,,,0,,,,,,testing,2017-09-13T18:00:00Z,,,,,\n",
+			n: 2,
+		},
+		{
+			name: "selected fields",
+			opts: export.Options{Fields: []string{export.FieldStatus, export.FieldUUID}},
+			it:   export.Slice(builds),
+			want: "status,uuid\n" +
+				"success,25a3dd8c-eb3e-4e75-1298-8cbcbe621342\n" +
+				"testing,25a3dd8c-eb3e-4e75-1298-8cbcbe611111\n",
+			n: 2,
+		},
+		{
+			name: "escapes formulas",
+			opts: export.Options{Fields: []string{export.FieldCommitMessage, export.FieldUsername, export.FieldRunDurationSeconds}},
+			it: export.Slice([]codeship.Build{
+				{CommitMessage: "=HYPERLINK(\"http://example.com\")", Username: "@user", AllocatedAt: time.Unix(10, 0), FinishedAt: time.Unix(5, 0)},
+				{CommitMessage: "+1", Username: "-user"},
+				{CommitMessage: "\tTab", Username: "user"},
+			}),
+			want: "commit_message,username,run_duration_seconds\n" +
+				"\"'=HYPERLINK(\"\"http://example.com\"\")\",'@user,-5\n" +
+				"'+1,'-user,\n" +
+				"'\tTab,user,\n",
+			n: 3,
+		},
+		{
+			name: "formula escaping disabled",
+			opts: export.Options{Fields: []string{export.FieldCommitMessage}, NoFormulaEscaping: true},
+			it:   export.Slice([]codeship.Build{{CommitMessage: "=1+1"}}),
+			want: "commit_message\n" +
+				"=1+1\n",
+			n: 1,
+		},
+		{
+			name: "unknown field",
+			opts: export.Options{Fields: []string{"foo"}},
+			it:   export.Slice(builds),
+			err:  `unknown field "foo"`,
+		},
+		{
+			name: "iterator error",
+			opts: export.Options{Fields: []string{export.FieldUUID}},
+			it:   failingIterator{export.Slice(builds)},
+			want: "uuid\n" +
+				"25a3dd8c-eb3e-4e75-1298-8cbcbe621342\n" +
+				"25a3dd8c-eb3e-4e75-1298-8cbcbe611111\n",
+			n:   2,
+			err: "unable to iterate builds: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := export.CSV(&buf, tt.it, tt.opts)
+
+			assert.Equal(t, tt.n, n)
+			assert.Equal(t, tt.want, buf.String())
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	tests := []struct {
+		name string
+		opts export.Options
+		it   export.Iterator
+		want string
+		n    int
+		err  string
+	}{
+		{
+			name: "default fields",
+			it:   export.Slice(builds),
+			want: `{"uuid":"25a3dd8c-eb3e-4e75-1298-8cbcbe621342","organization_uuid":"28123f10-e33d-5533-b53f-111ef8d7b14f","project_uuid":"0059df30-7701-0135-8810-6e5f001a2e3c","project_id":1,"branch":"master","ref":"heads/master","commit_sha":"185ab4c7dc4eda2a027c284f7a669cac3f50a5ed","commit_message":"Fix \"quoted\", comma","username":"fillup","status":"success","queued_at":"2017-09-13T17:13:00Z","allocated_at":"2017-09-13T17:13:30Z","finished_at":"2017-09-13T17:15:00Z","queue_duration_seconds":30,"run_duration_seconds":90.5,"total_duration_seconds":120.5}` + "\n" +
+				`{"uuid":"25a3dd8c-eb3e-4e75-1298-8cbcbe611111","organization_uuid":"","project_uuid":"","project_id":0,"branch":"","ref":"","commit_sha":"","commit_message":"","username":"","status":"testing","queued_at":"2017-09-13T18:00:00Z","allocated_at":null,"finished_at":null,"queue_duration_seconds":null,"run_duration_seconds":null,"total_duration_seconds":null}` + "\n",
+			n: 2,
+		},
+		{
+			name: "selected fields",
+			opts: export.Options{Fields: []string{export.FieldUUID, export.FieldTotalDurationSeconds}},
+			it:   export.Slice(builds[:1]),
+			want: `{"uuid":"25a3dd8c-eb3e-4e75-1298-8cbcbe621342","total_duration_seconds":120.5}` + "\n",
+			n:    1,
+		},
+		{
+			name: "unknown field",
+			opts: export.Options{Fields: []string{"foo"}},
+			it:   export.Slice(builds),
+			err:  `unknown field "foo"`,
+		},
+		{
+			name: "iterator error",
+			opts: export.Options{Fields: []string{export.FieldStatus}},
+			it:   failingIterator{export.Slice(builds)},
+			want: `{"status":"success"}` + "\n" + `{"status":"testing"}` + "\n",
+			n:    2,
+			err:  "unable to iterate builds: boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := export.JSONLines(&buf, tt.it, tt.opts)
+
+			assert.Equal(t, tt.n, n)
+			assert.Equal(t, tt.want, buf.String())
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package codeship
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrAmbiguousProject occurs when a query given to FindProject matches more
+// than one project
+type ErrAmbiguousProject struct {
+	Query      string
+	Candidates []Project
+}
+
+func (e ErrAmbiguousProject) Error() string {
+	candidates := make([]string, len(e.Candidates))
+	for i, p := range e.Candidates {
+		candidates[i] = fmt.Sprintf("%s (%s)", p.Name, p.UUID)
+	}
+	return fmt.Sprintf("project %q is ambiguous, candidates: %s", e.Query, strings.Join(candidates, ", "))
+}
+
+// projectCache holds the projects of each organization, keyed by UUID, for
+// FindProject
+type projectCache struct {
+	mu      sync.Mutex
+	entries map[string]projectCacheEntry
+}
+
+type projectCacheEntry struct {
+	projects  []Project
+	expiresAt time.Time
+}
+
+// FindProject finds a project by, in order of precedence:
+//
+//   - its UUID
+//   - its name, e.g. org/repo, ignoring case
+//   - its repository URL, ignoring the protocol, user and .git suffix, so
+//     that git@github.com:org/repo.git matches https://github.com/org/repo.
+//     The host may be omitted, e.g. org/repo matches any provider.
+//   - a prefix of its UUID
+//
+// Projects are listed from the API and cached for the duration set with the
+// ProjectCacheTTL option. If the query matches several projects with the same
+// precedence ErrAmbiguousProject is returned, and if it matches none
+// ErrNotFound is returned.
+func (o *Organization) FindProject(ctx context.Context, query string) (Project, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return Project{}, errors.New("unable to find project: no query provided")
+	}
+
+	projects, err := o.cachedProjects(ctx)
+	if err != nil {
+		return Project{}, errors.Wrap(err, "unable to find project")
+	}
+
+	q := strings.ToLower(query)
+	normalized := NormalizeRepositoryURL(query)
+	hasHost := strings.Contains(strings.SplitN(normalized, "/", 2)[0], ".")
+
+	matchers := []func(p Project) bool{
+		func(p Project) bool {
+			return strings.ToLower(p.UUID) == q
+		},
+		func(p Project) bool {
+			return strings.ToLower(p.Name) == q
+		},
+		func(p Project) bool {
+			url := NormalizeRepositoryURL(p.RepositoryURL)
+			if hasHost {
+				return url == normalized
+			}
+			return strings.HasSuffix(url, "/"+normalized)
+		},
+		func(p Project) bool {
+			return strings.HasPrefix(strings.ToLower(p.UUID), q)
+		},
+	}
+
+	for _, matches := range matchers {
+		var candidates []Project
+		for _, p := range projects {
+			if matches(p) {
+				candidates = append(candidates, p)
+			}
+		}
+
+		switch len(candidates) {
+		case 0:
+			continue
+		case 1:
+			return candidates[0], nil
+		default:
+			return Project{}, ErrAmbiguousProject{Query: query, Candidates: candidates}
+		}
+	}
+
+	return Project{}, ErrNotFound{apiErrors{Errors: []string{fmt.Sprintf("project %q not found", query)}}}
+}
+
+// cachedProjects returns every project of the organization, listing them from
+// the API if they are not cached or the cache expired
+func (o *Organization) cachedProjects(ctx context.Context) ([]Project, error) {
+	c := o.client.projectCache
+
+	c.mu.Lock()
+	entry, ok := c.entries[o.UUID]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.projects, nil
+	}
+
+	projects, err := o.ListAllProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if ttl := o.client.projectCacheTTL; ttl > 0 {
+		c.mu.Lock()
+		c.entries[o.UUID] = projectCacheEntry{projects: projects, expiresAt: time.Now().Add(ttl)}
+		c.mu.Unlock()
+	}
+
+	return projects, nil
+}
+
+// invalidateProjects removes the cached projects of the organization, so that
+// a project that was just created can be found
+func (o *Organization) invalidateProjects() {
+	c := o.client.projectCache
+
+	c.mu.Lock()
+	delete(c.entries, o.UUID)
+	c.mu.Unlock()
+}
+
+// NormalizeRepositoryURL reduces the forms of a repository URL to host/path so
+// that they can be compared, e.g. git@github.com:org/repo.git,
+// ssh://git@github.com/org/repo and https://github.com/org/repo/ all become
+// github.com/org/repo
+func NormalizeRepositoryURL(url string) string {
+	url = strings.ToLower(strings.TrimSpace(url))
+
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	} else if i := strings.Index(url, ":"); i >= 0 && !strings.Contains(url[:i], "/") {
+		// scp-like syntax, e.g. git@github.com:org/repo
+		url = url[:i] + "/" + url[i+1:]
+	}
+
+	if i := strings.Index(url, "@"); i >= 0 && !strings.Contains(url[:i], "/") {
+		url = url[i+1:]
+	}
+
+	// Drop the port, e.g. of ssh://git@example.com:7999/org/repo
+	parts := strings.SplitN(url, "/", 2)
+	if i := strings.Index(parts[0], ":"); i >= 0 {
+		parts[0] = parts[0][:i]
+	}
+	url = strings.Join(parts, "/")
+
+	url = strings.TrimSuffix(url, "/")
+	url = strings.TrimSuffix(url, ".git")
+	return url
+}
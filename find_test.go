@@ -0,0 +1,179 @@
+package codeship_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var findProjects = []codeship.Project{
+	{
+		Name:          "org/test-project",
+		RepositoryURL: "https://github.com/org/test-project",
+		UUID:          "0059df30-7701-0135-8810-6e5f001a2e3c",
+	},
+	{
+		Name:          "org/another-project",
+		RepositoryURL: "git@github.com:org/another-project.git",
+		UUID:          "83605ef0-76f8-0135-8810-6e5f001a2e3c",
+	},
+	{
+		Name:          "org/mirror",
+		RepositoryURL: "https://bitbucket.org/org/test-project",
+		UUID:          "0059ff00-2d13-0134-d0b4-1e1b0ba06dd1",
+	},
+}
+
+// handleFindProjects serves findProjects and counts the requests to list them
+func handleFindProjects(t *testing.T, requests *int32) {
+	mux.HandleFunc(fmt.Sprintf("/organizations/%s/projects", org.UUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "POST" {
+			fmt.Fprint(w, fixture("projects/create_pro.json"))
+			return
+		}
+
+		atomic.AddInt32(requests, 1)
+		require.NoError(t, json.NewEncoder(w).Encode(codeship.ProjectList{Projects: findProjects}))
+	})
+}
+
+func TestFindProject(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		uuid  string
+		err   string
+	}{
+		{
+			name:  "uuid",
+			query: "0059df30-7701-0135-8810-6e5f001a2e3c",
+			uuid:  "0059df30-7701-0135-8810-6e5f001a2e3c",
+		},
+		{
+			name:  "name ignoring case",
+			query: "ORG/Test-Project",
+			uuid:  "0059df30-7701-0135-8810-6e5f001a2e3c",
+		},
+		{
+			name:  "ssh url for https repository",
+			query: "git@github.com:org/test-project.git",
+			uuid:  "0059df30-7701-0135-8810-6e5f001a2e3c",
+		},
+		{
+			name:  "https url for ssh repository",
+			query: "https://github.com/org/another-project/",
+			uuid:  "83605ef0-76f8-0135-8810-6e5f001a2e3c",
+		},
+		{
+			name:  "ssh url with port",
+			query: "ssh://git@github.com:22/org/another-project",
+			uuid:  "83605ef0-76f8-0135-8810-6e5f001a2e3c",
+		},
+		{
+			name:  "url with provider",
+			query: "bitbucket.org/org/test-project",
+			uuid:  "0059ff00-2d13-0134-d0b4-1e1b0ba06dd1",
+		},
+		{
+			name:  "uuid prefix",
+			query: "8360",
+			uuid:  "83605ef0-76f8-0135-8810-6e5f001a2e3c",
+		},
+		{
+			name:  "ambiguous repository",
+			query: "test-project",
+			err:   `project "test-project" is ambiguous, candidates: org/test-project (0059df30-7701-0135-8810-6e5f001a2e3c), org/mirror (0059ff00-2d13-0134-d0b4-1e1b0ba06dd1)`,
+		},
+		{
+			name:  "ambiguous uuid prefix",
+			query: "0059",
+			err:   `project "0059" is ambiguous, candidates: org/test-project (0059df30-7701-0135-8810-6e5f001a2e3c), org/mirror (0059ff00-2d13-0134-d0b4-1e1b0ba06dd1)`,
+		},
+		{
+			name:  "not found",
+			query: "org/missing",
+			err:   `project "org/missing" not found`,
+		},
+		{
+			name:  "requires query",
+			query: " ",
+			err:   "unable to find project: no query provided",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardown := setup()
+			defer teardown()
+
+			var requests int32
+			handleFindProjects(t, &requests)
+
+			project, err := org.FindProject(context.Background(), tt.query)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.uuid, project.UUID)
+		})
+	}
+}
+
+func TestFindProject_Errors(t *testing.T) {
+	teardown := setup()
+	defer teardown()
+
+	var requests int32
+	handleFindProjects(t, &requests)
+
+	_, err := org.FindProject(context.Background(), "0059")
+	ambiguous, ok := errors.Cause(err).(codeship.ErrAmbiguousProject)
+	require.True(t, ok)
+	assert.Equal(t, "0059", ambiguous.Query)
+	assert.Len(t, ambiguous.Candidates, 2)
+
+	_, err = org.FindProject(context.Background(), "org/missing")
+	_, ok = errors.Cause(err).(codeship.ErrNotFound)
+	assert.True(t, ok)
+}
+
+func TestFindProject_Cache(t *testing.T) {
+	teardown := setup()
+	defer teardown()
+
+	var requests int32
+	handleFindProjects(t, &requests)
+
+	for i := 0; i < 3; i++ {
+		_, err := org.FindProject(context.Background(), "org/test-project")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests), "projects are cached")
+
+	_, _, err := org.CreateProject(context.Background(), codeship.ProjectCreateRequest{RepositoryURL: "git@github.com:org/new.git", Type: codeship.ProjectTypePro})
+	require.NoError(t, err)
+	_, err = org.FindProject(context.Background(), "org/test-project")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests), "creating a project invalidates the cache")
+
+	uncached, err := codeship.New(codeship.NewBasicAuth("test", "pass"), codeship.BaseURL(server.URL), codeship.ProjectCacheTTL(0))
+	require.NoError(t, err)
+	uncachedOrg, err := uncached.Organization(context.Background(), "codeship")
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err := uncachedOrg.FindProject(context.Background(), "org/test-project")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(4), atomic.LoadInt32(&requests), "caching disabled")
+}
@@ -12,4 +12,5 @@ require (
 	github.com/spf13/jwalterweatherman v1.1.0 // indirect
 	github.com/stretchr/testify v1.6.1
 	golang.org/x/tools v0.0.0-20200812195022-5ae4c3c160a0
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
@@ -0,0 +1,68 @@
+package codeship
+
+import (
+	"net/http"
+	"time"
+)
+
+// RequestInfo describes a single API request made by the client
+type RequestInfo struct {
+	// Operation is the name of the client method that made the request, e.g. "ListBuilds"
+	Operation string
+	Method    string
+	// Path is the request path relative to the API base URL, including any query string
+	Path string
+	// StatusCode is the HTTP status code of the response, or 0 if no response was received
+	StatusCode int
+	// Duration is the time taken to receive and read the response
+	Duration time.Duration
+	// Attempt is the 1-based attempt number of the request. The client does
+	// not retry requests, so it is always 1, including when a custom
+	// http.Client retries at the transport level.
+	Attempt int
+	// Cached is true if the response was served from the response cache
+	Cached bool
+	// Revalidated is true if a cached response was served after the API
+	// responded with 304 Not Modified. Cached responses that were not
+	// revalidated were served without contacting the API.
+	Revalidated bool
+	// Err is the error returned for the request, if any
+	Err error
+}
+
+// Hook holds callbacks that are invoked around every API request. Any of the
+// callbacks may be nil.
+type Hook struct {
+	// BeforeRequest is called before the request is sent. The request may be
+	// modified, e.g. to add headers.
+	BeforeRequest func(req *http.Request, info RequestInfo)
+	// AfterResponse is called once a response has been received. The response
+	// body has already been read and closed.
+	AfterResponse func(resp *http.Response, info RequestInfo)
+	// OnError is called when the request fails, either because no response was
+	// received or because the API returned an error status.
+	OnError func(err error, info RequestInfo)
+}
+
+// runBefore calls the BeforeRequest hooks in the order they were registered
+func (c *Client) runBefore(req *http.Request, info RequestInfo) {
+	for _, h := range c.hooks {
+		if h.BeforeRequest != nil {
+			h.BeforeRequest(req, info)
+		}
+	}
+}
+
+// runAfter calls the AfterResponse and OnError hooks in the reverse order they
+// were registered, so that the first hook registered wraps all others
+func (c *Client) runAfter(resp *http.Response, info RequestInfo) {
+	for i := len(c.hooks) - 1; i >= 0; i-- {
+		h := c.hooks[i]
+		if resp != nil && h.AfterResponse != nil {
+			h.AfterResponse(resp, info)
+		}
+		if info.Err != nil && h.OnError != nil {
+			h.OnError(info.Err, info)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package codeship_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		status  int
+		calls   []string
+		err     string
+	}{
+		{
+			name: "success",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "bar", r.Header.Get("X-Foo"))
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprint(w, fixture("builds/list.json"))
+			},
+			status: http.StatusOK,
+			calls: []string{
+				"first before ListBuilds",
+				"second before ListBuilds",
+				"second after ListBuilds 200",
+				"first after ListBuilds 200",
+			},
+		},
+		{
+			name: "error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, fixture("not_found.json"), "project")
+			},
+			status: http.StatusNotFound,
+			calls: []string{
+				"first before ListBuilds",
+				"second before ListBuilds",
+				"second after ListBuilds 404",
+				"second error ListBuilds project not found",
+				"first after ListBuilds 404",
+				"first error ListBuilds project not found",
+			},
+			err: "unable to list builds: project not found",
+		},
+	}
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardown := setup()
+			defer teardown()
+
+			mux.HandleFunc("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/28123f10-e33d-5533-b53f-111ef8d7b14f/builds", tt.handler)
+
+			var calls []string
+			hook := func(name string) codeship.Hook {
+				return codeship.Hook{
+					BeforeRequest: func(req *http.Request, info codeship.RequestInfo) {
+						req.Header.Set("X-Foo", "bar")
+						calls = append(calls, fmt.Sprintf("%s before %s", name, info.Operation))
+					},
+					AfterResponse: func(resp *http.Response, info codeship.RequestInfo) {
+						if info.Operation == "ListBuilds" {
+							assert.Equal("GET", info.Method)
+							assert.Equal("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/28123f10-e33d-5533-b53f-111ef8d7b14f/builds?page=2", info.Path)
+							assert.Equal(1, info.Attempt)
+							assert.True(info.Duration > 0)
+						}
+						calls = append(calls, fmt.Sprintf("%s after %s %d", name, info.Operation, info.StatusCode))
+					},
+					OnError: func(err error, info codeship.RequestInfo) {
+						calls = append(calls, fmt.Sprintf("%s error %s %v", name, info.Operation, err))
+					},
+				}
+			}
+
+			c, err := codeship.New(codeship.NewBasicAuth("test", "pass"), codeship.BaseURL(server.URL), codeship.Hooks(hook("first"), hook("second")))
+			require.NoError(err)
+
+			o, err := c.Organization(context.Background(), "codeship")
+			require.NoError(err)
+
+			// discard calls made during authentication
+			calls = nil
+
+			_, resp, err := o.ListBuilds(context.Background(), "28123f10-e33d-5533-b53f-111ef8d7b14f", codeship.Page(2))
+
+			require.NotNil(resp)
+			assert.Equal(tt.status, resp.StatusCode)
+			assert.Equal(tt.calls, calls)
+
+			if tt.err != "" {
+				assert.EqualError(err, tt.err)
+				return
+			}
+			require.NoError(err)
+		})
+	}
+}
+
+func TestHooksAuthenticate(t *testing.T) {
+	teardown := setup()
+	defer teardown()
+
+	var ops []string
+	c, err := codeship.New(codeship.NewBasicAuth("test", "pass"), codeship.BaseURL(server.URL), codeship.Hooks(codeship.Hook{
+		AfterResponse: func(resp *http.Response, info codeship.RequestInfo) {
+			ops = append(ops, info.Operation+" "+info.Path)
+		},
+	}))
+	require.NoError(t, err)
+
+	_, err = c.Authenticate(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Authenticate /auth"}, ops)
+}
@@ -0,0 +1,40 @@
+package fileutil_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codeship/codeship-go/internal/fileutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "codeship-fileutil")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "file")
+	require.NoError(t, ioutil.WriteFile(path, []byte("old"), 0644))
+
+	require.NoError(t, fileutil.WriteFileAtomic(path, []byte("new"), 0600))
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(b))
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	infos, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, infos, 1, "expected temporary file to be removed")
+}
+
+func TestWriteFileAtomic_Error(t *testing.T) {
+	err := fileutil.WriteFileAtomic(filepath.Join("missing", "dir", "file"), []byte("data"), 0600)
+	assert.Error(t, err)
+}
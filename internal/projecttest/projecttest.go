@@ -0,0 +1,94 @@
+// Package projecttest provides a fake project client for tests of the
+// packages of this module that list, create and update projects.
+package projecttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	codeship "github.com/codeship/codeship-go"
+)
+
+// Client stores projects in memory and applies requests like the API,
+// ignoring empty fields in update requests. If Err is set, every method
+// returns it.
+type Client struct {
+	Projects []codeship.Project
+	Creates  int
+	Updates  []codeship.ProjectUpdateRequest
+	Err      error
+}
+
+// ListAllProjects returns a copy of Projects
+func (c *Client) ListAllProjects(ctx context.Context) ([]codeship.Project, error) {
+	return append([]codeship.Project(nil), c.Projects...), c.Err
+}
+
+// GetProject returns the project with the given UUID
+func (c *Client) GetProject(ctx context.Context, projectUUID string) (codeship.Project, codeship.Response, error) {
+	if c.Err != nil {
+		return codeship.Project{}, codeship.Response{}, c.Err
+	}
+	for _, p := range c.Projects {
+		if p.UUID == projectUUID {
+			return p, codeship.Response{}, nil
+		}
+	}
+	return codeship.Project{}, codeship.Response{}, errors.New("project not found")
+}
+
+// CreateProject adds a project, named after its repository URL and with
+// numbered test pipelines
+func (c *Client) CreateProject(ctx context.Context, req codeship.ProjectCreateRequest) (codeship.Project, codeship.Response, error) {
+	if c.Err != nil {
+		return codeship.Project{}, codeship.Response{}, c.Err
+	}
+	c.Creates++
+	p := codeship.Project{
+		UUID:                 fmt.Sprintf("created-%d", c.Creates),
+		Name:                 req.RepositoryURL,
+		RepositoryURL:        req.RepositoryURL,
+		Type:                 req.Type,
+		TeamIDs:              req.TeamIDs,
+		SetupCommands:        req.SetupCommands,
+		EnvironmentVariables: req.EnvironmentVariables,
+		NotificationRules:    req.NotificationRules,
+		TestPipelines:        req.TestPipelines,
+	}
+	for i := range p.TestPipelines {
+		p.TestPipelines[i].ID = i + 1
+	}
+	c.Projects = append(c.Projects, p)
+	return p, codeship.Response{}, nil
+}
+
+// UpdateProject records the request and applies its non-empty fields to the
+// project with the given UUID
+func (c *Client) UpdateProject(ctx context.Context, projectUUID string, req codeship.ProjectUpdateRequest) (codeship.Project, codeship.Response, error) {
+	if c.Err != nil {
+		return codeship.Project{}, codeship.Response{}, c.Err
+	}
+	c.Updates = append(c.Updates, req)
+	for i, p := range c.Projects {
+		if p.UUID != projectUUID {
+			continue
+		}
+		p.Type = req.Type
+		if len(req.TeamIDs) > 0 {
+			p.TeamIDs = req.TeamIDs
+		}
+		if len(req.SetupCommands) > 0 {
+			p.SetupCommands = req.SetupCommands
+		}
+		if len(req.EnvironmentVariables) > 0 {
+			p.EnvironmentVariables = req.EnvironmentVariables
+		}
+		if len(req.NotificationRules) > 0 {
+			p.NotificationRules = req.NotificationRules
+		}
+		c.Projects[i] = p
+		return p, codeship.Response{}, nil
+	}
+	return codeship.Project{}, codeship.Response{}, errors.New("project not found")
+}
@@ -0,0 +1,88 @@
+package codeship
+
+import (
+	"context"
+)
+
+// BuildIterator iterates over all builds of a project, newest first, fetching
+// pages from the API as needed
+type BuildIterator struct {
+	org         *Organization
+	projectUUID string
+	opts        []PaginationOption
+
+	ctx    context.Context
+	page   int
+	builds []Build
+	build  Build
+	done   bool
+	err    error
+}
+
+// IterateBuilds returns an iterator over all builds of a project. PerPage can
+// be provided to control how many builds are fetched per request.
+//
+//	it := org.IterateBuilds(ctx, projectUUID)
+//	for it.Next() {
+//	    build := it.Build()
+//	}
+//	if err := it.Err(); err != nil {
+//	    ...
+//	}
+func (o *Organization) IterateBuilds(ctx context.Context, projectUUID string, opts ...PaginationOption) *BuildIterator {
+	return &BuildIterator{
+		org:         o,
+		projectUUID: projectUUID,
+		opts:        opts,
+		ctx:         ctx,
+		page:        1,
+	}
+}
+
+// Next advances the iterator to the next build, returning false when there are
+// no more builds or an error occurred
+func (it *BuildIterator) Next() bool {
+	for len(it.builds) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetch()
+	}
+
+	it.build, it.builds = it.builds[0], it.builds[1:]
+	return true
+}
+
+// Build returns the current build
+func (it *BuildIterator) Build() Build {
+	return it.build
+}
+
+// Err returns the first error encountered while iterating
+func (it *BuildIterator) Err() error {
+	return it.err
+}
+
+func (it *BuildIterator) fetch() {
+	opts := append(it.opts[:len(it.opts):len(it.opts)], Page(it.page))
+
+	list, resp, err := it.org.ListBuilds(it.ctx, it.projectUUID, opts...)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	it.builds = list.Builds
+
+	next, err := resp.NextPage()
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	if next == 0 || len(list.Builds) == 0 {
+		it.done = true
+		return
+	}
+	it.page = next
+}
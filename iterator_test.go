@@ -0,0 +1,85 @@
+package codeship_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIterateBuilds(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []codeship.PaginationOption
+		handler http.HandlerFunc
+		want    []string
+		err     string
+	}{
+		{
+			name: "multiple pages",
+			opts: []codeship.PaginationOption{codeship.PerPage(1)},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "1", r.URL.Query().Get("per_page"))
+
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Query().Get("page") {
+				case "1":
+					w.Header().Set("Link", `<https://api.codeship.com/v2/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/28123f10-e33d-5533-b53f-111ef8d7b14f/builds?page=2>; rel="next", <https://api.codeship.com/v2/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/28123f10-e33d-5533-b53f-111ef8d7b14f/builds?page=2>; rel="last"`)
+					fmt.Fprint(w, `{"builds": [{"uuid": "build-2"}]}`)
+				case "2":
+					w.Header().Set("Link", `<https://api.codeship.com/v2/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/28123f10-e33d-5533-b53f-111ef8d7b14f/builds?page=1>; rel="prev", <https://api.codeship.com/v2/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/28123f10-e33d-5533-b53f-111ef8d7b14f/builds?page=1>; rel="first"`)
+					fmt.Fprint(w, `{"builds": [{"uuid": "build-1"}]}`)
+				default:
+					t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+				}
+			},
+			want: []string{"build-2", "build-1"},
+		},
+		{
+			name: "single page",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"builds": [{"uuid": "build-2"}, {"uuid": "build-1"}]}`)
+			},
+			want: []string{"build-2", "build-1"},
+		},
+		{
+			name: "error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, fixture("not_found.json"), "project")
+			},
+			err: "unable to list builds: project not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardown := setup()
+			defer teardown()
+
+			mux.HandleFunc("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/28123f10-e33d-5533-b53f-111ef8d7b14f/builds", tt.handler)
+
+			it := org.IterateBuilds(context.Background(), "28123f10-e33d-5533-b53f-111ef8d7b14f", tt.opts...)
+
+			var got []string
+			for it.Next() {
+				got = append(got, it.Build().UUID)
+			}
+
+			assert.Equal(t, tt.want, got)
+
+			if tt.err != "" {
+				require.Error(t, it.Err())
+				assert.EqualError(t, it.Err(), tt.err)
+				return
+			}
+			require.NoError(t, it.Err())
+		})
+	}
+}
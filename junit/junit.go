@@ -0,0 +1,214 @@
+// Package junit converts the steps of a Codeship Pro build into a JUnit XML
+// report, so that Codeship results can be ingested by test report aggregators.
+//
+//	steps, _, err := org.ListBuildSteps(ctx, projectUUID, buildUUID)
+//	services, _, err := org.ListBuildServices(ctx, projectUUID, buildUUID)
+//
+//	report := junit.Convert(build, steps.Steps, services.Services)
+//	_, err = report.WriteTo(f)
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+)
+
+// TestSuites is the root element of a JUnit XML report
+type TestSuites struct {
+	XMLName  xml.Name    `xml:"testsuites"`
+	Name     string      `xml:"name,attr,omitempty"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     Seconds     `xml:"time,attr"`
+	Suites   []TestSuite `xml:"testsuite"`
+}
+
+// TestSuite groups the test cases for a service or step group
+type TestSuite struct {
+	Name      string     `xml:"name,attr"`
+	Tests     int        `xml:"tests,attr"`
+	Failures  int        `xml:"failures,attr"`
+	Errors    int        `xml:"errors,attr"`
+	Skipped   int        `xml:"skipped,attr"`
+	Time      Seconds    `xml:"time,attr"`
+	Timestamp string     `xml:"timestamp,attr,omitempty"`
+	Cases     []TestCase `xml:"testcase"`
+}
+
+// TestCase is a single leaf step
+type TestCase struct {
+	Name      string   `xml:"name,attr"`
+	ClassName string   `xml:"classname,attr"`
+	Time      Seconds  `xml:"time,attr"`
+	Failure   *Result  `xml:"failure,omitempty"`
+	Error     *Result  `xml:"error,omitempty"`
+	Skipped   *Skipped `xml:"skipped,omitempty"`
+	SystemOut string   `xml:"system-out,omitempty"`
+}
+
+// Result describes why a test case failed
+type Result struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// Skipped marks a test case that did not run to completion
+type Skipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// Seconds is a duration formatted as seconds, as expected by JUnit consumers
+type Seconds time.Duration
+
+// MarshalXMLAttr implements xml.MarshalerAttr
+func (s Seconds) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	return xml.Attr{
+		Name:  name,
+		Value: strconv.FormatFloat(time.Duration(s).Seconds(), 'f', 3, 64),
+	}, nil
+}
+
+const defaultSuite = "steps"
+
+// Convert builds a JUnit report for a build from its steps. Each top level
+// step group becomes a test suite, and top level steps outside of a group are
+// collected into a test suite per service. Every leaf step becomes a test case.
+// Services are only used to name suites and may be nil.
+func Convert(build codeship.Build, steps []codeship.BuildStep, services []codeship.BuildService) TestSuites {
+	serviceNames := make(map[string]string, len(services))
+	for _, s := range services {
+		serviceNames[s.UUID] = s.Name
+	}
+
+	report := TestSuites{Name: build.UUID}
+
+	// Preserve the order in which suites first appear
+	index := make(map[string]int)
+	suite := func(name string) *TestSuite {
+		i, ok := index[name]
+		if !ok {
+			i = len(report.Suites)
+			index[name] = i
+			report.Suites = append(report.Suites, TestSuite{Name: name})
+		}
+		return &report.Suites[i]
+	}
+
+	for _, step := range steps {
+		if len(step.Steps) > 0 {
+			s := suite(stepName(step))
+			for _, leaf := range leaves(step.Steps) {
+				s.add(leaf)
+			}
+			continue
+		}
+
+		name := serviceNames[step.ServiceUUID]
+		if name == "" {
+			name = step.ServiceUUID
+		}
+		if name == "" {
+			name = defaultSuite
+		}
+		suite(name).add(step)
+	}
+
+	for _, s := range report.Suites {
+		report.Tests += s.Tests
+		report.Failures += s.Failures
+		report.Errors += s.Errors
+		report.Skipped += s.Skipped
+		report.Time += s.Time
+	}
+
+	return report
+}
+
+// WriteTo writes the report to w as an indented XML document
+func (r TestSuites) WriteTo(w io.Writer) (int64, error) {
+	b, err := xml.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := fmt.Fprintf(w, "%s%s\n", xml.Header, b)
+	return int64(n), err
+}
+
+func (s *TestSuite) add(step codeship.BuildStep) {
+	started := step.StartedAt
+	if started.IsZero() {
+		started = step.BuildingAt
+	}
+
+	tc := TestCase{
+		Name:      stepName(step),
+		ClassName: s.Name,
+		SystemOut: step.Command,
+	}
+	if !started.IsZero() && !step.FinishedAt.IsZero() {
+		tc.Time = Seconds(step.FinishedAt.Sub(started))
+	}
+
+	switch step.Status {
+	case codeship.StepStatusSuccess:
+	case codeship.StepStatusError:
+		tc.Failure = &Result{
+			Message: fmt.Sprintf("step failed with status %q", step.Status),
+			Type:    step.Status,
+			Body:    step.Command,
+		}
+		s.Failures++
+	case codeship.StepStatusInfrastructureFailure:
+		tc.Error = &Result{
+			Message: fmt.Sprintf("step failed with status %q", step.Status),
+			Type:    step.Status,
+			Body:    step.Command,
+		}
+		s.Errors++
+	default:
+		tc.Skipped = &Skipped{Message: fmt.Sprintf("step did not complete, status %q", step.Status)}
+		s.Skipped++
+	}
+
+	if !started.IsZero() && (s.Timestamp == "" || started.UTC().Format(time.RFC3339) < s.Timestamp) {
+		s.Timestamp = started.UTC().Format(time.RFC3339)
+	}
+
+	s.Tests++
+	s.Time += tc.Time
+	s.Cases = append(s.Cases, tc)
+}
+
+// leaves returns the steps without nested steps, in order
+func leaves(steps []codeship.BuildStep) []codeship.BuildStep {
+	var flat []codeship.BuildStep
+	for _, step := range steps {
+		if len(step.Steps) > 0 {
+			flat = append(flat, leaves(step.Steps)...)
+			continue
+		}
+		flat = append(flat, step)
+	}
+	return flat
+}
+
+func stepName(step codeship.BuildStep) string {
+	switch {
+	case step.Name != "":
+		return step.Name
+	case step.Command != "":
+		return step.Command
+	case step.Type != "":
+		return step.Type
+	}
+	return step.UUID
+}
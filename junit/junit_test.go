@@ -0,0 +1,124 @@
+package junit_test
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/junit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func at(sec int) time.Time {
+	return time.Date(2017, 9, 13, 17, 13, sec, 0, time.UTC)
+}
+
+var (
+	build = codeship.Build{UUID: "25a3dd8c-eb3e-4e75-1298-8cbcbe621342"}
+
+	services = []codeship.BuildService{
+		{UUID: "b46c6c6c-1bdb-4413-8e55-a9a8b1b27526", Name: "app"},
+	}
+
+	steps = []codeship.BuildStep{
+		{
+			Name:        "lint",
+			Command:     "./scripts/lint",
+			ServiceUUID: "b46c6c6c-1bdb-4413-8e55-a9a8b1b27526",
+			Status:      codeship.StepStatusSuccess,
+			StartedAt:   at(0),
+			FinishedAt:  at(5),
+		},
+		{
+			Name:   "tests",
+			Type:   "parallel",
+			Status: codeship.StepStatusError,
+			Steps: []codeship.BuildStep{
+				{
+					Name:        "unit",
+					Command:     "go test ./...",
+					ServiceUUID: "b46c6c6c-1bdb-4413-8e55-a9a8b1b27526",
+					Status:      codeship.StepStatusError,
+					StartedAt:   at(10),
+					FinishedAt:  at(20),
+				},
+				{
+					Type:   "serial",
+					Status: codeship.StepStatusError,
+					Steps: []codeship.BuildStep{
+						{
+							Name:       "integration",
+							Command:    "./scripts/integration",
+							Status:     codeship.StepStatusInfrastructureFailure,
+							BuildingAt: at(6),
+							FinishedAt: at(8),
+						},
+						{
+							Name:   "e2e",
+							Status: codeship.StepStatusStopped,
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "deploy",
+			Command:     "./scripts/deploy",
+			ServiceUUID: "c3a1e2b0-0000-4000-8000-000000000000",
+			Status:      codeship.StepStatusSuccess,
+			StartedAt:   at(30),
+			FinishedAt:  at(31),
+		},
+	}
+)
+
+func TestConvert(t *testing.T) {
+	report := junit.Convert(build, steps, services)
+
+	assert.Equal(t, "25a3dd8c-eb3e-4e75-1298-8cbcbe621342", report.Name)
+	assert.Equal(t, 5, report.Tests)
+	assert.Equal(t, 1, report.Failures)
+	assert.Equal(t, 1, report.Errors)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, junit.Seconds(18*time.Second), report.Time)
+
+	require.Len(t, report.Suites, 3)
+	assert.Equal(t, "app", report.Suites[0].Name)
+	assert.Equal(t, "tests", report.Suites[1].Name)
+	assert.Equal(t, "c3a1e2b0-0000-4000-8000-000000000000", report.Suites[2].Name)
+
+	var names []string
+	for _, tc := range report.Suites[1].Cases {
+		names = append(names, tc.Name)
+	}
+	assert.Equal(t, []string{"unit", "integration", "e2e"}, names)
+}
+
+func TestWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := junit.Convert(build, steps, services).WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	golden := "testdata/report.xml"
+	if *update {
+		require.NoError(t, ioutil.WriteFile(golden, buf.Bytes(), 0644))
+	}
+
+	want, err := ioutil.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), buf.String())
+}
+
+func TestConvertEmpty(t *testing.T) {
+	report := junit.Convert(build, nil, nil)
+
+	assert.Equal(t, 0, report.Tests)
+	assert.Empty(t, report.Suites)
+}
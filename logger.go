@@ -0,0 +1,78 @@
+package codeship
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LeveledLogger allows you to bring your own structured log implementation.
+// Arguments after the message are alternating keys and values, matching the
+// conventions of log/slog, so a *slog.Logger can be used directly.
+type LeveledLogger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+}
+
+// logRequest logs a completed request to the structured logger, if configured.
+// Successful requests are logged at debug level and failures at warn level.
+func (c *Client) logRequest(resp *http.Response, info RequestInfo) {
+	if c.leveledLogger == nil {
+		return
+	}
+
+	kv := []interface{}{
+		"operation", info.Operation,
+		"method", info.Method,
+		"path", info.Path,
+		"status", info.StatusCode,
+		"latency", info.Duration,
+		// Always 0, the client does not retry requests
+		"retries", info.Attempt - 1,
+	}
+
+	if resp != nil {
+		if id := resp.Header.Get("X-Request-Id"); id != "" {
+			kv = append(kv, "request_id", id)
+		}
+	}
+
+	if info.Err != nil {
+		c.leveledLogger.Warn("codeship API request failed", append(kv, "error", info.Err.Error())...)
+		return
+	}
+	c.leveledLogger.Debug("codeship API request", kv...)
+}
+
+// NewLogrusLogger adapts a logrus logger to the LeveledLogger interface, mapping
+// key/value pairs to logrus fields
+func NewLogrusLogger(logger logrus.FieldLogger) LeveledLogger {
+	return &logrusLogger{logger: logger}
+}
+
+type logrusLogger struct {
+	logger logrus.FieldLogger
+}
+
+func (l *logrusLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.logger.WithFields(toFields(keysAndValues)).Debug(msg)
+}
+
+func (l *logrusLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.logger.WithFields(toFields(keysAndValues)).Warn(msg)
+}
+
+// toFields converts alternating keys and values to logrus fields. A key without
+// a value is logged under "!BADKEY", as log/slog does.
+func toFields(keysAndValues []interface{}) logrus.Fields {
+	fields := make(logrus.Fields, len(keysAndValues)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 == len(keysAndValues) {
+			fields["!BADKEY"] = keysAndValues[i]
+			break
+		}
+		fields[fmt.Sprint(keysAndValues[i])] = keysAndValues[i+1]
+	}
+	return fields
+}
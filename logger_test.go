@@ -0,0 +1,139 @@
+package codeship_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type logEntry struct {
+	level  string
+	msg    string
+	fields map[string]interface{}
+}
+
+// recordingLogger is a LeveledLogger that records every entry
+type recordingLogger struct {
+	entries []logEntry
+}
+
+func (l *recordingLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.record("debug", msg, keysAndValues)
+}
+
+func (l *recordingLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.record("warn", msg, keysAndValues)
+}
+
+func (l *recordingLogger) record(level, msg string, keysAndValues []interface{}) {
+	fields := make(map[string]interface{})
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[keysAndValues[i].(string)] = keysAndValues[i+1]
+	}
+	l.entries = append(l.entries, logEntry{level: level, msg: msg, fields: fields})
+}
+
+func TestStructuredLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		level   string
+		msg     string
+		status  int
+		err     string
+	}{
+		{
+			name: "success",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Request-Id", "abc-123")
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprint(w, fixture("builds/get.json"))
+			},
+			level:  "debug",
+			msg:    "codeship API request",
+			status: http.StatusOK,
+		},
+		{
+			name: "error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Request-Id", "abc-123")
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, fixture("not_found.json"), "build")
+			},
+			level:  "warn",
+			msg:    "codeship API request failed",
+			status: http.StatusNotFound,
+			err:    "build not found",
+		},
+	}
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardown := setup()
+			defer teardown()
+
+			mux.HandleFunc("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/28123f10-e33d-5533-b53f-111ef8d7b14f/builds/25a3dd8c-eb3e-4e75-1298-8cbcbe621342", tt.handler)
+
+			logger := &recordingLogger{}
+			c, err := codeship.New(codeship.NewBasicAuth("test", "pass"), codeship.BaseURL(server.URL), codeship.StructuredLogger(logger))
+			require.NoError(err)
+
+			o, err := c.Organization(context.Background(), "codeship")
+			require.NoError(err)
+
+			_, _, _ = o.GetBuild(context.Background(), "28123f10-e33d-5533-b53f-111ef8d7b14f", "25a3dd8c-eb3e-4e75-1298-8cbcbe621342")
+
+			require.Len(logger.entries, 2)
+			assert.Equal("Authenticate", logger.entries[0].fields["operation"])
+
+			entry := logger.entries[1]
+			assert.Equal(tt.level, entry.level)
+			assert.Equal(tt.msg, entry.msg)
+			assert.Equal("GetBuild", entry.fields["operation"])
+			assert.Equal("GET", entry.fields["method"])
+			assert.Equal("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/28123f10-e33d-5533-b53f-111ef8d7b14f/builds/25a3dd8c-eb3e-4e75-1298-8cbcbe621342", entry.fields["path"])
+			assert.Equal(tt.status, entry.fields["status"])
+			assert.Equal("abc-123", entry.fields["request_id"])
+			assert.Equal(0, entry.fields["retries"])
+			assert.Contains(entry.fields, "latency")
+
+			if tt.err != "" {
+				assert.Equal(tt.err, entry.fields["error"])
+				return
+			}
+			assert.NotContains(entry.fields, "error")
+		})
+	}
+}
+
+func TestLogrusLogger(t *testing.T) {
+	logger, hook := logrustest.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+
+	l := codeship.NewLogrusLogger(logger)
+	l.Debug("debug message", "status", 200, "path", "/auth")
+	l.Warn("warn message", "error", "boom", "dangling")
+
+	entries := hook.AllEntries()
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, logrus.DebugLevel, entries[0].Level)
+	assert.Equal(t, "debug message", entries[0].Message)
+	assert.Equal(t, logrus.Fields{"status": 200, "path": "/auth"}, entries[0].Data)
+
+	assert.Equal(t, logrus.WarnLevel, entries[1].Level)
+	assert.Equal(t, "warn message", entries[1].Message)
+	assert.Equal(t, logrus.Fields{"error": "boom", "!BADKEY": "dangling"}, entries[1].Data)
+}
@@ -0,0 +1,62 @@
+// Package metrics instruments a Codeship API client, counting requests,
+// latencies, rate-limited responses and token refreshes.
+//
+// Usage:
+//
+//	registry := metrics.NewRegistry()
+//	client, err := codeship.New(auth, metrics.Instrument(registry))
+//
+//	http.Handle("/metrics", registry)
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+)
+
+// Collector records metrics about API requests made by a client
+type Collector interface {
+	// ObserveRequest records a completed request. status is 0 if no response was received.
+	ObserveRequest(operation string, status int, duration time.Duration)
+	// IncRateLimited records a request rejected because of the API rate limit
+	IncRateLimited(operation string)
+	// IncTokenRefreshes records a successful authentication
+	IncTokenRefreshes()
+}
+
+const authenticateOperation = "Authenticate"
+
+// Instrument returns a client Option that reports metrics for every request to the collector
+func Instrument(c Collector) codeship.Option {
+	return codeship.Hooks(codeship.Hook{
+		AfterResponse: func(resp *http.Response, info codeship.RequestInfo) {
+			observe(c, info)
+		},
+		OnError: func(err error, info codeship.RequestInfo) {
+			// Requests that received a response have already been observed
+			if info.StatusCode == 0 {
+				observe(c, info)
+			}
+		},
+	})
+}
+
+func observe(c Collector, info codeship.RequestInfo) {
+	// Responses served from the cache without contacting the API are not requests
+	if info.Cached && !info.Revalidated {
+		return
+	}
+
+	c.ObserveRequest(info.Operation, info.StatusCode, info.Duration)
+
+	switch info.StatusCode {
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		c.IncRateLimited(info.Operation)
+	}
+
+	if info.Operation == authenticateOperation && info.Err == nil {
+		c.IncTokenRefreshes()
+	}
+}
@@ -0,0 +1,137 @@
+package metrics_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCollector records calls made to it
+type fakeCollector struct {
+	requests       []string
+	rateLimited    []string
+	tokenRefreshes int
+}
+
+func (f *fakeCollector) ObserveRequest(operation string, status int, duration time.Duration) {
+	f.requests = append(f.requests, fmt.Sprintf("%s %d", operation, status))
+}
+
+func (f *fakeCollector) IncRateLimited(operation string) {
+	f.rateLimited = append(f.rateLimited, operation)
+}
+
+func (f *fakeCollector) IncTokenRefreshes() {
+	f.tokenRefreshes++
+}
+
+func TestInstrument(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"access_token": "token", "expires_at": 9999999999, "organizations": [{"name": "codeship", "uuid": "28123f10-e33d-5533-b53f-111ef8d7b14f"}]}`)
+	})
+	mux.HandleFunc("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"projects": []}`)
+	})
+	mux.HandleFunc("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/foo", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	collector := &fakeCollector{}
+	client, err := codeship.New(codeship.NewBasicAuth("username", "password"), codeship.BaseURL(server.URL), metrics.Instrument(collector))
+	require.NoError(t, err)
+
+	org, err := client.Organization(context.Background(), "codeship")
+	require.NoError(t, err)
+
+	_, _, err = org.ListProjects(context.Background())
+	require.NoError(t, err)
+
+	_, _, err = org.GetProject(context.Background(), "foo")
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"Authenticate 200", "ListProjects 200", "GetProject 429"}, collector.requests)
+	assert.Equal(t, []string{"GetProject"}, collector.rateLimited)
+	assert.Equal(t, 1, collector.tokenRefreshes)
+}
+
+func TestInstrumentResponseCache(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"access_token": "token", "expires_at": 9999999999, "organizations": [{"name": "codeship", "uuid": "28123f10-e33d-5533-b53f-111ef8d7b14f"}]}`)
+	})
+	mux.HandleFunc("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"abc"`)
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"projects": []}`)
+	})
+	mux.HandleFunc("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/foo/builds/bar", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"build": {"uuid": "bar", "status": "success"}}`)
+	})
+
+	collector := &fakeCollector{}
+	client, err := codeship.New(codeship.NewBasicAuth("username", "password"),
+		codeship.BaseURL(server.URL),
+		codeship.ResponseCache(codeship.NewMemoryCache()),
+		codeship.ImmutableCacheTTL(time.Hour),
+		metrics.Instrument(collector),
+	)
+	require.NoError(t, err)
+
+	org, err := client.Organization(context.Background(), "codeship")
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, _, err = org.ListProjects(context.Background())
+		require.NoError(t, err)
+
+		_, _, err = org.GetBuild(context.Background(), "foo", "bar")
+		require.NoError(t, err)
+	}
+
+	// The finished build is served from the cache without contacting the API
+	// the second time, while the project list is revalidated
+	assert.Equal(t, []string{"Authenticate 200", "ListProjects 200", "GetBuild 200", "ListProjects 200"}, collector.requests)
+}
+
+func TestInstrumentTransportError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	collector := &fakeCollector{}
+	client, err := codeship.New(codeship.NewBasicAuth("username", "password"), codeship.BaseURL(server.URL), metrics.Instrument(collector))
+	require.NoError(t, err)
+
+	_, err = client.Authenticate(context.Background())
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"Authenticate 0"}, collector.requests)
+	assert.Equal(t, 0, collector.tokenRefreshes)
+}
@@ -0,0 +1,191 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the default latency histogram buckets in seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Registry is an in-memory Collector that exposes its metrics in the
+// Prometheus text exposition format. It is safe for concurrent use.
+type Registry struct {
+	mu sync.Mutex
+
+	buckets        []float64
+	requests       map[requestKey]uint64
+	latencies      map[string]*histogram
+	rateLimited    map[string]uint64
+	tokenRefreshes uint64
+}
+
+type requestKey struct {
+	operation string
+	status    int
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewRegistry creates a new Registry. If no buckets are provided, DefaultBuckets are used.
+func NewRegistry(buckets ...float64) *Registry {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	b := make([]float64, len(buckets))
+	copy(b, buckets)
+	sort.Float64s(b)
+
+	return &Registry{
+		buckets:     b,
+		requests:    make(map[requestKey]uint64),
+		latencies:   make(map[string]*histogram),
+		rateLimited: make(map[string]uint64),
+	}
+}
+
+// ObserveRequest implements Collector
+func (r *Registry) ObserveRequest(operation string, status int, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests[requestKey{operation: operation, status: status}]++
+
+	h, ok := r.latencies[operation]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(r.buckets))}
+		r.latencies[operation] = h
+	}
+
+	seconds := duration.Seconds()
+	for i, upper := range r.buckets {
+		if seconds <= upper {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// IncRateLimited implements Collector
+func (r *Registry) IncRateLimited(operation string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rateLimited[operation]++
+}
+
+// IncTokenRefreshes implements Collector
+func (r *Registry) IncTokenRefreshes() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokenRefreshes++
+}
+
+// WriteTo writes all metrics to w in the Prometheus text exposition format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	header(cw, "codeship_requests_total", "counter", "Total number of Codeship API requests by operation and HTTP status code.")
+	keys := make([]requestKey, 0, len(r.requests))
+	for k := range r.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].operation != keys[j].operation {
+			return keys[i].operation < keys[j].operation
+		}
+		return keys[i].status < keys[j].status
+	})
+	for _, k := range keys {
+		fmt.Fprintf(cw, "codeship_requests_total{code=%q,operation=%q} %d\n", strconv.Itoa(k.status), k.operation, r.requests[k])
+	}
+
+	header(cw, "codeship_request_duration_seconds", "histogram", "Latency of Codeship API requests by operation.")
+	for _, op := range sortedKeys(r.latencies) {
+		h := r.latencies[op]
+		for i, upper := range r.buckets {
+			fmt.Fprintf(cw, "codeship_request_duration_seconds_bucket{operation=%q,le=%q} %d\n", op, formatFloat(upper), h.counts[i])
+		}
+		fmt.Fprintf(cw, "codeship_request_duration_seconds_bucket{operation=%q,le=\"+Inf\"} %d\n", op, h.count)
+		fmt.Fprintf(cw, "codeship_request_duration_seconds_sum{operation=%q} %s\n", op, formatFloat(h.sum))
+		fmt.Fprintf(cw, "codeship_request_duration_seconds_count{operation=%q} %d\n", op, h.count)
+	}
+
+	header(cw, "codeship_rate_limited_total", "counter", "Total number of rate limited Codeship API requests by operation.")
+	writeCounters(cw, "codeship_rate_limited_total", r.rateLimited)
+
+	header(cw, "codeship_token_refreshes_total", "counter", "Total number of Codeship API access token refreshes.")
+	fmt.Fprintf(cw, "codeship_token_refreshes_total %d\n", r.tokenRefreshes)
+
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+	return cw.n, cw.w.Flush()
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = r.WriteTo(w)
+}
+
+func header(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func writeCounters(w io.Writer, name string, counters map[string]uint64) {
+	ops := make([]string, 0, len(counters))
+	for op := range counters {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		fmt.Fprintf(w, "%s{operation=%q} %d\n", name, op, counters[op])
+	}
+}
+
+func sortedKeys(m map[string]*histogram) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// countingWriter tracks the number of bytes written and the first error encountered
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,67 @@
+package metrics_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/codeship/codeship-go/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryWriteTo(t *testing.T) {
+	r := metrics.NewRegistry(0.1, 1)
+
+	r.ObserveRequest("ListBuilds", 200, 50*time.Millisecond)
+	r.ObserveRequest("ListBuilds", 200, 500*time.Millisecond)
+	r.ObserveRequest("ListBuilds", 429, 2*time.Second)
+	r.ObserveRequest("Authenticate", 200, 10*time.Millisecond)
+	r.IncRateLimited("ListBuilds")
+	r.IncTokenRefreshes()
+
+	var buf bytes.Buffer
+	n, err := r.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	expected := `# HELP codeship_requests_total Total number of Codeship API requests by operation and HTTP status code.
+# TYPE codeship_requests_total counter
+codeship_requests_total{code="200",operation="Authenticate"} 1
+codeship_requests_total{code="200",operation="ListBuilds"} 2
+codeship_requests_total{code="429",operation="ListBuilds"} 1
+# HELP codeship_request_duration_seconds Latency of Codeship API requests by operation.
+# TYPE codeship_request_duration_seconds histogram
+codeship_request_duration_seconds_bucket{operation="Authenticate",le="0.1"} 1
+codeship_request_duration_seconds_bucket{operation="Authenticate",le="1"} 1
+codeship_request_duration_seconds_bucket{operation="Authenticate",le="+Inf"} 1
+codeship_request_duration_seconds_sum{operation="Authenticate"} 0.01
+codeship_request_duration_seconds_count{operation="Authenticate"} 1
+codeship_request_duration_seconds_bucket{operation="ListBuilds",le="0.1"} 1
+codeship_request_duration_seconds_bucket{operation="ListBuilds",le="1"} 2
+codeship_request_duration_seconds_bucket{operation="ListBuilds",le="+Inf"} 3
+codeship_request_duration_seconds_sum{operation="ListBuilds"} 2.55
+codeship_request_duration_seconds_count{operation="ListBuilds"} 3
+# HELP codeship_rate_limited_total Total number of rate limited Codeship API requests by operation.
+# TYPE codeship_rate_limited_total counter
+codeship_rate_limited_total{operation="ListBuilds"} 1
+# HELP codeship_token_refreshes_total Total number of Codeship API access token refreshes.
+# TYPE codeship_token_refreshes_total counter
+codeship_token_refreshes_total 1
+`
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestRegistryServeHTTP(t *testing.T) {
+	r := metrics.NewRegistry()
+	r.IncTokenRefreshes()
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "codeship_token_refreshes_total 1\n")
+}
@@ -0,0 +1,345 @@
+package codeship
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Notifier is the service a NotificationRule notifies
+type Notifier string
+
+// Notifiers supported by NotificationRule
+const (
+	NotifierBitbucket Notifier = "bitbucket"
+	NotifierCampfire  Notifier = "campfire"
+	NotifierEmail     Notifier = "email"
+	NotifierFlowdock  Notifier = "flowdock"
+	NotifierGitHub    Notifier = "github"
+	NotifierGitLab    Notifier = "gitlab"
+	NotifierHipChat   Notifier = "hipchat"
+	NotifierSlack     Notifier = "slack"
+	NotifierWebhook   Notifier = "webhook"
+)
+
+// NotifyOn is a build status that a NotificationRule can be triggered by
+type NotifyOn string
+
+// Build statuses that a NotificationRule can be triggered by
+const (
+	NotifyOnStarted   NotifyOn = "started"
+	NotifyOnSuccess   NotifyOn = "success"
+	NotifyOnFailed    NotifyOn = "failed"
+	NotifyOnRecovered NotifyOn = "recovered"
+)
+
+// Branch match modes for NotificationRule
+const (
+	BranchMatchExact  = "exact"
+	BranchMatchPrefix = "prefix"
+)
+
+// Targets for NotificationRule
+const (
+	NotificationTargetAll       = "all"
+	NotificationTargetCommitter = "committer"
+)
+
+var (
+	_notifiers = map[Notifier]bool{
+		NotifierBitbucket: true,
+		NotifierCampfire:  true,
+		NotifierEmail:     true,
+		NotifierFlowdock:  true,
+		NotifierGitHub:    true,
+		NotifierGitLab:    true,
+		NotifierHipChat:   true,
+		NotifierSlack:     true,
+		NotifierWebhook:   true,
+	}
+	_notifyOn = map[NotifyOn]bool{
+		NotifyOnStarted:   true,
+		NotifyOnSuccess:   true,
+		NotifyOnFailed:    true,
+		NotifyOnRecovered: true,
+	}
+	_branchMatches = map[string]bool{
+		BranchMatchExact:  true,
+		BranchMatchPrefix: true,
+	}
+	_notificationTargets = map[string]bool{
+		NotificationTargetAll:       true,
+		NotificationTargetCommitter: true,
+	}
+)
+
+// EmailNotification creates a rule that emails target on the given build statuses
+func EmailNotification(target string, statuses ...NotifyOn) NotificationRule {
+	return NotificationRule{
+		Notifier:      NotifierEmail,
+		Target:        target,
+		BranchMatch:   BranchMatchExact,
+		BuildStatuses: statuses,
+	}
+}
+
+// SlackNotification creates a rule that posts to a Slack incoming webhook URL
+func SlackNotification(webhookURL string, statuses ...NotifyOn) NotificationRule {
+	return urlNotification(NotifierSlack, webhookURL, statuses)
+}
+
+// WebhookNotification creates a rule that posts build payloads to a URL
+func WebhookNotification(webhookURL string, statuses ...NotifyOn) NotificationRule {
+	return urlNotification(NotifierWebhook, webhookURL, statuses)
+}
+
+// HipChatNotification creates a rule that posts to a HipChat room
+func HipChatNotification(key, room string, statuses ...NotifyOn) NotificationRule {
+	return keyNotification(NotifierHipChat, key, room, statuses)
+}
+
+// CampfireNotification creates a rule that posts to a Campfire room
+func CampfireNotification(key, room string, statuses ...NotifyOn) NotificationRule {
+	return keyNotification(NotifierCampfire, key, room, statuses)
+}
+
+// FlowdockNotification creates a rule that posts to a Flowdock flow
+func FlowdockNotification(key string, statuses ...NotifyOn) NotificationRule {
+	return keyNotification(NotifierFlowdock, key, "", statuses)
+}
+
+// GitHubNotification creates a rule that reports build statuses to GitHub
+func GitHubNotification(statuses ...NotifyOn) NotificationRule {
+	return statusNotification(NotifierGitHub, statuses)
+}
+
+// BitbucketNotification creates a rule that reports build statuses to Bitbucket
+func BitbucketNotification(statuses ...NotifyOn) NotificationRule {
+	return statusNotification(NotifierBitbucket, statuses)
+}
+
+// GitLabNotification creates a rule that reports build statuses to GitLab
+func GitLabNotification(statuses ...NotifyOn) NotificationRule {
+	return statusNotification(NotifierGitLab, statuses)
+}
+
+func urlNotification(notifier Notifier, u string, statuses []NotifyOn) NotificationRule {
+	return NotificationRule{
+		Notifier:      notifier,
+		Target:        NotificationTargetAll,
+		BranchMatch:   BranchMatchExact,
+		BuildStatuses: statuses,
+		Options:       NotificationOptions{URL: u},
+	}
+}
+
+func keyNotification(notifier Notifier, key, room string, statuses []NotifyOn) NotificationRule {
+	return NotificationRule{
+		Notifier:      notifier,
+		Target:        NotificationTargetAll,
+		BranchMatch:   BranchMatchExact,
+		BuildStatuses: statuses,
+		Options:       NotificationOptions{Key: key, Room: room},
+	}
+}
+
+func statusNotification(notifier Notifier, statuses []NotifyOn) NotificationRule {
+	return NotificationRule{
+		Notifier:      notifier,
+		Target:        NotificationTargetAll,
+		BranchMatch:   BranchMatchExact,
+		BuildStatuses: statuses,
+	}
+}
+
+// OnBranch returns a copy of the rule that only applies to builds of branches
+// matching branch, according to match
+func (r NotificationRule) OnBranch(branch, match string) NotificationRule {
+	r.Branch = branch
+	r.BranchMatch = match
+	return r
+}
+
+// FieldError is a validation error for a single field, identified by its JSON path
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// ValidationErrors holds all field errors found while validating a value
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, ", ")
+}
+
+// Validate checks the rule for errors that would otherwise be rejected by the
+// API. It returns ValidationErrors if any are found.
+func (r NotificationRule) Validate() error {
+	var errs ValidationErrors
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case r.Notifier == "":
+		add("notifier", "is required")
+	case !_notifiers[r.Notifier]:
+		add("notifier", "is not supported: %q", r.Notifier)
+	}
+
+	if r.BranchMatch != "" && !_branchMatches[r.BranchMatch] {
+		add("branch_match", "is not supported: %q", r.BranchMatch)
+	}
+
+	if r.Target != "" && !_notificationTargets[r.Target] {
+		add("target", "is not supported: %q", r.Target)
+	}
+
+	if len(r.BuildStatuses) == 0 {
+		add("build_statuses", "is required")
+	}
+	for i, s := range r.BuildStatuses {
+		if !_notifyOn[s] {
+			add(fmt.Sprintf("build_statuses[%d]", i), "is not supported: %q", s)
+		}
+	}
+
+	switch r.Notifier {
+	case NotifierSlack, NotifierWebhook:
+		if r.Options.URL == "" {
+			add("options.url", "is required for %s notifications", r.Notifier)
+		} else if u, err := url.Parse(r.Options.URL); err != nil || !u.IsAbs() || u.Host == "" {
+			add("options.url", "must be an absolute URL")
+		}
+	case NotifierHipChat, NotifierCampfire:
+		if r.Options.Key == "" {
+			add("options.key", "is required for %s notifications", r.Notifier)
+		}
+		if r.Options.Room == "" {
+			add("options.room", "is required for %s notifications", r.Notifier)
+		}
+	case NotifierFlowdock:
+		if r.Options.Key == "" {
+			add("options.key", "is required for %s notifications", r.Notifier)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// equal reports whether two rules notify the same notifier, options and target
+// for the same branches and build statuses. Build statuses are compared as
+// sets, as their order has no meaning.
+func (r NotificationRule) equal(other NotificationRule) bool {
+	return r.Branch == other.Branch &&
+		r.BranchMatch == other.BranchMatch &&
+		r.Notifier == other.Notifier &&
+		r.Options == other.Options &&
+		r.Target == other.Target &&
+		sameStatuses(r.BuildStatuses, other.BuildStatuses)
+}
+
+// sameStatuses reports whether a and b contain the same build statuses,
+// ignoring order and duplicates
+func sameStatuses(a, b []NotifyOn) bool {
+	inA := make(map[NotifyOn]bool, len(a))
+	for _, s := range a {
+		inA[s] = true
+	}
+	inB := make(map[NotifyOn]bool, len(b))
+	for _, s := range b {
+		if !inA[s] {
+			return false
+		}
+		inB[s] = true
+	}
+	return len(inA) == len(inB)
+}
+
+// notificationRulesUpdateRequest is sent by AddNotificationRule and
+// RemoveNotificationRule. Unlike ProjectUpdateRequest, an empty list of rules
+// is sent so that the last rule of a project can be removed.
+type notificationRulesUpdateRequest struct {
+	NotificationRules []NotificationRule `json:"notification_rules"`
+	Type              ProjectType        `json:"type"`
+}
+
+// AddNotificationRule validates rule and adds it to the notification rules of
+// a project, keeping the existing rules. The project is returned unchanged if
+// an identical rule already exists.
+func (o *Organization) AddNotificationRule(ctx context.Context, projectUUID string, rule NotificationRule) (Project, Response, error) {
+	if err := rule.Validate(); err != nil {
+		return Project{}, Response{}, errors.Wrap(err, "invalid notification rule")
+	}
+
+	project, resp, err := o.GetProject(ctx, projectUUID)
+	if err != nil {
+		return Project{}, resp, err
+	}
+
+	for _, r := range project.NotificationRules {
+		if r.equal(rule) {
+			return project, resp, nil
+		}
+	}
+
+	rules := append(project.NotificationRules[:len(project.NotificationRules):len(project.NotificationRules)], rule)
+	return o.updateNotificationRules(ctx, project, rules)
+}
+
+// RemoveNotificationRule removes all notification rules of a project that are
+// identical to rule, keeping the others. An error is returned if no rule matches.
+func (o *Organization) RemoveNotificationRule(ctx context.Context, projectUUID string, rule NotificationRule) (Project, Response, error) {
+	project, resp, err := o.GetProject(ctx, projectUUID)
+	if err != nil {
+		return Project{}, resp, err
+	}
+
+	rules := make([]NotificationRule, 0, len(project.NotificationRules))
+	for _, r := range project.NotificationRules {
+		if !r.equal(rule) {
+			rules = append(rules, r)
+		}
+	}
+
+	if len(rules) == len(project.NotificationRules) {
+		return Project{}, resp, errors.New("unable to remove notification rule: rule not found")
+	}
+
+	return o.updateNotificationRules(ctx, project, rules)
+}
+
+func (o *Organization) updateNotificationRules(ctx context.Context, project Project, rules []NotificationRule) (Project, Response, error) {
+	path := fmt.Sprintf("/organizations/%s/projects/%s", o.UUID, project.UUID)
+
+	body, resp, err := o.request(ctx, "UpdateProject", "PUT", path, notificationRulesUpdateRequest{
+		NotificationRules: rules,
+		Type:              project.Type,
+	})
+	if err != nil {
+		return Project{}, resp, errors.Wrap(err, "unable to update project")
+	}
+
+	var updated projectResponse
+	if err = json.Unmarshal(body, &updated); err != nil {
+		return Project{}, resp, errors.Wrap(err, "unable to unmarshal response into Project")
+	}
+
+	return updated.Project, resp, nil
+}
@@ -0,0 +1,252 @@
+package codeship_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationRule_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   codeship.NotificationRule
+		fields []string
+	}{
+		{
+			name: "email",
+			rule: codeship.EmailNotification(codeship.NotificationTargetAll, codeship.NotifyOnFailed, codeship.NotifyOnRecovered),
+		},
+		{
+			name: "slack on branch",
+			rule: codeship.SlackNotification("https://hooks.slack.com/services/T000/B000/XXX", codeship.NotifyOnFailed).
+				OnBranch("release/", codeship.BranchMatchPrefix),
+		},
+		{
+			name: "webhook",
+			rule: codeship.WebhookNotification("https://example.com/codeship", codeship.NotifyOnStarted, codeship.NotifyOnSuccess),
+		},
+		{
+			name: "hipchat",
+			rule: codeship.HipChatNotification("key", "devs", codeship.NotifyOnFailed),
+		},
+		{
+			name: "github",
+			rule: codeship.GitHubNotification(codeship.NotifyOnStarted, codeship.NotifyOnSuccess, codeship.NotifyOnFailed),
+		},
+		{
+			name:   "missing notifier",
+			rule:   codeship.NotificationRule{BuildStatuses: []codeship.NotifyOn{codeship.NotifyOnFailed}},
+			fields: []string{"notifier"},
+		},
+		{
+			name:   "unknown notifier",
+			rule:   codeship.NotificationRule{Notifier: "slak", BuildStatuses: []codeship.NotifyOn{codeship.NotifyOnFailed}},
+			fields: []string{"notifier"},
+		},
+		{
+			name:   "missing statuses",
+			rule:   codeship.GitHubNotification(),
+			fields: []string{"build_statuses"},
+		},
+		{
+			name:   "unknown status",
+			rule:   codeship.GitHubNotification(codeship.NotifyOnFailed, "error"),
+			fields: []string{"build_statuses[1]"},
+		},
+		{
+			name:   "invalid branch match and target",
+			rule:   codeship.EmailNotification("everyone", codeship.NotifyOnFailed).OnBranch("master", "regex"),
+			fields: []string{"branch_match", "target"},
+		},
+		{
+			name:   "missing url",
+			rule:   codeship.SlackNotification("", codeship.NotifyOnFailed),
+			fields: []string{"options.url"},
+		},
+		{
+			name:   "relative url",
+			rule:   codeship.WebhookNotification("/codeship", codeship.NotifyOnFailed),
+			fields: []string{"options.url"},
+		},
+		{
+			name:   "missing key and room",
+			rule:   codeship.CampfireNotification("", "", codeship.NotifyOnFailed),
+			fields: []string{"options.key", "options.room"},
+		},
+		{
+			name:   "missing flowdock key",
+			rule:   codeship.FlowdockNotification("", codeship.NotifyOnFailed),
+			fields: []string{"options.key"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if len(tt.fields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			errs, ok := err.(codeship.ValidationErrors)
+			require.True(t, ok, "expected ValidationErrors, got %T", err)
+
+			var fields []string
+			for _, fe := range errs {
+				fields = append(fields, fe.Field)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
+
+func TestValidationErrors_Error(t *testing.T) {
+	err := codeship.ValidationErrors{
+		{Field: "notifier", Message: "is required"},
+		{Field: "options.url", Message: "must be an absolute URL"},
+	}
+
+	assert.EqualError(t, err, "notifier is required, options.url must be an absolute URL")
+}
+
+func TestAddNotificationRule(t *testing.T) {
+	projectUUID := "0059df30-7701-0135-8810-6e5f001a2e3c"
+
+	tests := []struct {
+		name  string
+		rule  codeship.NotificationRule
+		rules int
+		err   string
+	}{
+		{
+			name:  "success",
+			rule:  codeship.SlackNotification("https://hooks.slack.com/services/T000/B000/XXX", codeship.NotifyOnFailed),
+			rules: 3,
+		},
+		{
+			name: "already exists",
+			rule: codeship.EmailNotification(codeship.NotificationTargetAll, "failed", "recovered"),
+		},
+		{
+			name: "already exists with reordered statuses",
+			rule: codeship.EmailNotification(codeship.NotificationTargetAll, "recovered", "failed"),
+		},
+		{
+			name: "invalid",
+			rule: codeship.SlackNotification("", codeship.NotifyOnFailed),
+			err:  "invalid notification rule: options.url is required for slack notifications",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardown := setup()
+			defer teardown()
+
+			var updated bool
+			mux.HandleFunc(fmt.Sprintf("/organizations/%s/projects/%s", org.UUID, projectUUID), func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+
+				if r.Method == "GET" {
+					fmt.Fprint(w, fixture("projects/get.json"))
+					return
+				}
+
+				assert.Equal(t, "PUT", r.Method)
+				updated = true
+
+				var req struct {
+					NotificationRules []codeship.NotificationRule `json:"notification_rules"`
+					Type              codeship.ProjectType        `json:"type"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Equal(t, codeship.ProjectTypePro, req.Type)
+				require.Len(t, req.NotificationRules, tt.rules)
+				assert.Equal(t, codeship.NotifierGitHub, req.NotificationRules[0].Notifier)
+				assert.Equal(t, tt.rule, req.NotificationRules[tt.rules-1])
+
+				fmt.Fprint(w, fixture("projects/update.json"))
+			})
+
+			project, _, err := org.AddNotificationRule(context.Background(), projectUUID, tt.rule)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				assert.False(t, updated)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.rules > 0, updated)
+			assert.NotEmpty(t, project.UUID)
+		})
+	}
+}
+
+func TestRemoveNotificationRule(t *testing.T) {
+	projectUUID := "0059df30-7701-0135-8810-6e5f001a2e3c"
+
+	tests := []struct {
+		name  string
+		rule  codeship.NotificationRule
+		rules int
+		err   string
+	}{
+		{
+			name:  "success",
+			rule:  codeship.EmailNotification(codeship.NotificationTargetAll, "failed", "recovered"),
+			rules: 1,
+		},
+		{
+			name:  "reordered statuses",
+			rule:  codeship.EmailNotification(codeship.NotificationTargetAll, "recovered", "failed"),
+			rules: 1,
+		},
+		{
+			name: "not found",
+			rule: codeship.EmailNotification(codeship.NotificationTargetAll, "failed"),
+			err:  "unable to remove notification rule: rule not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardown := setup()
+			defer teardown()
+
+			var updated bool
+			mux.HandleFunc(fmt.Sprintf("/organizations/%s/projects/%s", org.UUID, projectUUID), func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+
+				if r.Method == "GET" {
+					fmt.Fprint(w, fixture("projects/get.json"))
+					return
+				}
+
+				updated = true
+
+				var req struct {
+					NotificationRules []codeship.NotificationRule `json:"notification_rules"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				require.Len(t, req.NotificationRules, tt.rules)
+				assert.Equal(t, codeship.NotifierGitHub, req.NotificationRules[0].Notifier)
+
+				fmt.Fprint(w, fixture("projects/update.json"))
+			})
+
+			_, _, err := org.RemoveNotificationRule(context.Background(), projectUUID, tt.rule)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				assert.False(t, updated)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, updated)
+		})
+	}
+}
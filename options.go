@@ -2,6 +2,7 @@ package codeship
 
 import (
 	"net/http"
+	"time"
 )
 
 // Option is a functional option for configuring the API client
@@ -24,6 +25,16 @@ func Headers(headers http.Header) Option {
 	}
 }
 
+// Hooks registers callbacks that are invoked around every API request, e.g. for
+// collecting metrics, tracing or custom logging. Hooks are appended to any
+// previously registered hooks.
+func Hooks(hooks ...Hook) Option {
+	return func(c *Client) error {
+		c.hooks = append(c.hooks, hooks...)
+		return nil
+	}
+}
+
 // BaseURL allows overriding of API client baseURL for testing
 func BaseURL(baseURL string) Option {
 	return func(c *Client) error {
@@ -32,6 +43,26 @@ func BaseURL(baseURL string) Option {
 	}
 }
 
+// ResponseCache enables caching of GET responses. Cached responses are
+// revalidated with If-None-Match and If-Modified-Since headers, and served from
+// the cache when the API responds with 304 Not Modified.
+func ResponseCache(cache Cache) Option {
+	return func(c *Client) error {
+		c.cache = cache
+		return nil
+	}
+}
+
+// ImmutableCacheTTL sets how long responses for resources that can no longer
+// change, such as finished builds, are served from the response cache without
+// revalidation. Defaults to 0, which always revalidates.
+func ImmutableCacheTTL(ttl time.Duration) Option {
+	return func(c *Client) error {
+		c.immutableTTL = ttl
+		return nil
+	}
+}
+
 // Logger allows overriding the default STDOUT logger
 func Logger(logger StdLogger) Option {
 	return func(c *Client) error {
@@ -40,6 +71,26 @@ func Logger(logger StdLogger) Option {
 	}
 }
 
+// StructuredLogger enables structured logging of every request made by the
+// client. Successful requests are logged at debug level with the operation,
+// method, path, status, latency and request ID as fields, and failed requests
+// are logged at warn level along with the error.
+func StructuredLogger(logger LeveledLogger) Option {
+	return func(c *Client) error {
+		c.leveledLogger = logger
+		return nil
+	}
+}
+
+// Tracing starts a span for every API call using the provided Tracer and
+// propagates the trace context to the Codeship API via request headers
+func Tracing(tracer Tracer) Option {
+	return func(c *Client) error {
+		c.tracer = tracer
+		return nil
+	}
+}
+
 // Verbose allows enabling/disabling internal logging
 func Verbose(verbose bool) Option {
 	return func(c *Client) error {
@@ -48,6 +99,48 @@ func Verbose(verbose bool) Option {
 	}
 }
 
+// ProjectCacheTTL sets how long FindProject caches the projects of an
+// organization. Defaults to 5 minutes, and a TTL of 0 disables caching.
+func ProjectCacheTTL(ttl time.Duration) Option {
+	return func(c *Client) error {
+		c.projectCacheTTL = ttl
+		return nil
+	}
+}
+
+// ScopeCheck enables or disables checking that an Organization has the scope
+// required by a method before sending the request. Checking is enabled by
+// default, and methods called without the required scope return
+// ErrInsufficientScope.
+func ScopeCheck(enabled bool) Option {
+	return func(c *Client) error {
+		c.skipScopeCheck = !enabled
+		return nil
+	}
+}
+
+// RedactHeaders sets the HTTP headers whose values are redacted from verbose
+// logs, replacing the defaults (Authorization, Cookie, Proxy-Authorization and
+// Set-Cookie). Calling it with no headers disables header redaction.
+func RedactHeaders(headers ...string) Option {
+	return func(c *Client) error {
+		c.redactedHeaders = headers
+		return nil
+	}
+}
+
+// RedactFields sets the JSON fields whose values are redacted from verbose
+// logs, replacing the defaults. A field may be qualified with its parent
+// objects, e.g. "environment_variables.value" only redacts "value" fields
+// nested under "environment_variables". Calling it with no fields disables
+// body redaction.
+func RedactFields(fields ...string) Option {
+	return func(c *Client) error {
+		c.redactedFields = fields
+		return nil
+	}
+}
+
 // parseOptions parses the supplied options functions and returns a configured
 // *Client instance
 func (c *Client) parseOptions(opts ...Option) error {
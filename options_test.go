@@ -198,3 +198,33 @@ func TestVerbose(t *testing.T) {
 		})
 	}
 }
+
+func TestHooks(t *testing.T) {
+	first := Hook{BeforeRequest: func(*http.Request, RequestInfo) {}}
+	second := Hook{OnError: func(error, RequestInfo) {}}
+
+	codeship, err := New(NewBasicAuth("username", "password"), Hooks(first), Hooks(second))
+
+	require.NoError(t, err)
+	assert.Len(t, codeship.hooks, 2)
+}
+
+func TestScopeCheck(t *testing.T) {
+	codeship, err := New(NewBasicAuth("username", "password"))
+	require.NoError(t, err)
+	assert.False(t, codeship.skipScopeCheck)
+
+	codeship, err = New(NewBasicAuth("username", "password"), ScopeCheck(false))
+	require.NoError(t, err)
+	assert.True(t, codeship.skipScopeCheck)
+}
+
+func TestProjectCacheTTL(t *testing.T) {
+	codeship, err := New(NewBasicAuth("username", "password"))
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, codeship.projectCacheTTL)
+
+	codeship, err = New(NewBasicAuth("username", "password"), ProjectCacheTTL(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, codeship.projectCacheTTL)
+}
@@ -0,0 +1,214 @@
+package codeship
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Organizations returns every organization the client is authorized for, in
+// the order returned when authenticating
+func (c *Client) Organizations(ctx context.Context) ([]*Organization, error) {
+	auth, err := c.authenticated(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "authentication failed")
+	}
+
+	orgs := make([]*Organization, 0, len(auth.Organizations))
+	for _, org := range auth.Organizations {
+		orgs = append(orgs, &Organization{
+			UUID:   org.UUID,
+			Name:   org.Name,
+			Scopes: org.Scopes,
+			client: c,
+		})
+	}
+	return orgs, nil
+}
+
+// OrganizationByUUID scopes a client to the Organization with the given UUID
+func (c *Client) OrganizationByUUID(ctx context.Context, uuid string) (*Organization, error) {
+	if uuid == "" {
+		return nil, errors.New("no organization uuid provided")
+	}
+
+	orgs, err := c.Organizations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, org := range orgs {
+		if strings.EqualFold(org.UUID, uuid) {
+			return org, nil
+		}
+	}
+	return nil, ErrUnauthorized(fmt.Sprintf("organization %q not authorized. Authorized organizations: %v", uuid, c.Authentication().Organizations))
+}
+
+// OrganizationError is the error of a single organization in a request made
+// across organizations
+type OrganizationError struct {
+	Organization *Organization
+	Err          error
+}
+
+func (e OrganizationError) Error() string {
+	return fmt.Sprintf("organization %s: %v", e.Organization.Name, e.Err)
+}
+
+// Cause returns the underlying error, for use with errors.Cause
+func (e OrganizationError) Cause() error {
+	return e.Err
+}
+
+// OrganizationErrors holds the errors of every organization that failed in a
+// request made across organizations
+type OrganizationErrors []OrganizationError
+
+func (e OrganizationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// maxConcurrentOrganizations is the number of organizations that requests
+// made across organizations query at the same time
+const maxConcurrentOrganizations = 4
+
+// ForEachOrganization calls fn concurrently for every organization the client
+// is authorized for, for at most 4 organizations at a time, and waits for all
+// calls to return. Errors returned by fn are collected into
+// OrganizationErrors, in the order of the organizations, so a failing
+// organization does not stop the others.
+func (c *Client) ForEachOrganization(ctx context.Context, fn func(ctx context.Context, org *Organization) error) error {
+	orgs, err := c.Organizations(ctx)
+	if err != nil {
+		return err
+	}
+
+	return forEachOrganization(ctx, orgs, func(ctx context.Context, _ int, org *Organization) error {
+		return fn(ctx, org)
+	})
+}
+
+// forEachOrganization calls fn concurrently with the index of each
+// organization, so that results can be stored in order without locking. At
+// most maxConcurrentOrganizations calls run at the same time.
+func forEachOrganization(ctx context.Context, orgs []*Organization, fn func(ctx context.Context, i int, org *Organization) error) error {
+	errs := make([]error, len(orgs))
+	sem := make(chan struct{}, maxConcurrentOrganizations)
+	var wg sync.WaitGroup
+	for i, org := range orgs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, org *Organization) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = fn(ctx, i, org)
+		}(i, org)
+	}
+	wg.Wait()
+
+	var orgErrs OrganizationErrors
+	for i, err := range errs {
+		if err != nil {
+			orgErrs = append(orgErrs, OrganizationError{Organization: orgs[i], Err: err})
+		}
+	}
+	if len(orgErrs) > 0 {
+		return orgErrs
+	}
+	return nil
+}
+
+// OrganizationProjects are the projects of a single organization
+type OrganizationProjects struct {
+	Organization *Organization
+	Projects     []Project
+}
+
+// ListProjectsByOrganization lists every project of every organization
+// concurrently. Projects of the organizations that succeeded are returned, in
+// the order of the organizations, along with OrganizationErrors for those that
+// failed.
+func (c *Client) ListProjectsByOrganization(ctx context.Context) ([]OrganizationProjects, error) {
+	orgs, err := c.Organizations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]OrganizationProjects, len(orgs))
+	err = forEachOrganization(ctx, orgs, func(ctx context.Context, i int, org *Organization) error {
+		projects, err := org.ListAllProjects(ctx)
+		if err != nil {
+			return err
+		}
+		results[i] = OrganizationProjects{Organization: org, Projects: projects}
+		return nil
+	})
+
+	succeeded := results[:0]
+	for _, r := range results {
+		if r.Organization != nil {
+			succeeded = append(succeeded, r)
+		}
+	}
+	return succeeded, err
+}
+
+// OrganizationBuilds are the builds of a single organization
+type OrganizationBuilds struct {
+	Organization *Organization
+	Builds       []Build
+}
+
+// ListRunningBuilds lists the builds that have not finished, across every
+// project of every organization. Organizations are queried concurrently and
+// only the most recent page of builds of each project is checked. Builds of
+// the organizations that succeeded are returned, in the order of the
+// organizations, along with OrganizationErrors for those that failed.
+func (c *Client) ListRunningBuilds(ctx context.Context) ([]OrganizationBuilds, error) {
+	orgs, err := c.Organizations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]OrganizationBuilds, len(orgs))
+	err = forEachOrganization(ctx, orgs, func(ctx context.Context, i int, org *Organization) error {
+		projects, err := org.ListAllProjects(ctx)
+		if err != nil {
+			return err
+		}
+
+		var running []Build
+		for _, p := range projects {
+			list, _, err := org.ListBuilds(ctx, p.UUID, PerPage(50))
+			if err != nil {
+				return errors.Wrapf(err, "project %s", p.Name)
+			}
+			for _, b := range list.Builds {
+				if !b.Finished() {
+					running = append(running, b)
+				}
+			}
+		}
+
+		results[i] = OrganizationBuilds{Organization: org, Builds: running}
+		return nil
+	})
+
+	succeeded := results[:0]
+	for _, r := range results {
+		if r.Organization != nil {
+			succeeded = append(succeeded, r)
+		}
+	}
+	return succeeded, err
+}
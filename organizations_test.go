@@ -0,0 +1,263 @@
+package codeship_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const templatesUUID = "7e4b5c1a-3f2d-4b8e-9a61-0c5d2e8f4a37"
+
+// setupOrganizations starts a server authorizing the client for the codeship
+// and templates organizations
+func setupOrganizations(t *testing.T, opts ...codeship.Option) (*http.ServeMux, *codeship.Client, func()) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, fixture("auth/multiple_organizations.json"))
+	})
+
+	client, err := codeship.New(codeship.NewBasicAuth("test", "pass"), append(opts, codeship.BaseURL(server.URL))...)
+	require.NoError(t, err)
+
+	return mux, client, server.Close
+}
+
+func TestOrganizations(t *testing.T) {
+	_, client, teardown := setupOrganizations(t)
+	defer teardown()
+
+	orgs, err := client.Organizations(context.Background())
+	require.NoError(t, err)
+	require.Len(t, orgs, 2)
+
+	assert.Equal(t, "codeship", orgs[0].Name)
+	assert.Equal(t, "28123f10-e33d-5533-b53f-111ef8d7b14f", orgs[0].UUID)
+	assert.Equal(t, []string{"project.read", "project.write", "build.read", "build.write"}, orgs[0].Scopes)
+	assert.Equal(t, "templates", orgs[1].Name)
+	assert.Equal(t, []string{"project.read", "build.read"}, orgs[1].Scopes)
+}
+
+func TestOrganizationByUUID(t *testing.T) {
+	tests := []struct {
+		name string
+		uuid string
+		org  string
+		err  string
+	}{
+		{
+			name: "success",
+			uuid: templatesUUID,
+			org:  "templates",
+		},
+		{
+			name: "case insensitive",
+			uuid: strings.ToUpper(templatesUUID),
+			org:  "templates",
+		},
+		{
+			name: "requires uuid",
+			err:  "no organization uuid provided",
+		},
+		{
+			name: "not authorized",
+			uuid: "c38f3280-2d13-0134-d0b4-1e1b0ba06dd1",
+			err:  `organization "c38f3280-2d13-0134-d0b4-1e1b0ba06dd1" not authorized.`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client, teardown := setupOrganizations(t)
+			defer teardown()
+
+			org, err := client.OrganizationByUUID(context.Background(), tt.uuid)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.org, org.Name)
+		})
+	}
+}
+
+func TestForEachOrganization(t *testing.T) {
+	_, client, teardown := setupOrganizations(t)
+	defer teardown()
+
+	var mu sync.Mutex
+	var called []string
+	boom := errors.New("boom")
+
+	err := client.ForEachOrganization(context.Background(), func(ctx context.Context, org *codeship.Organization) error {
+		mu.Lock()
+		called = append(called, org.Name)
+		mu.Unlock()
+
+		if org.Name == "templates" {
+			return boom
+		}
+		return nil
+	})
+
+	assert.ElementsMatch(t, []string{"codeship", "templates"}, called)
+	require.Error(t, err)
+	assert.EqualError(t, err, "organization templates: boom")
+
+	orgErrs, ok := err.(codeship.OrganizationErrors)
+	require.True(t, ok)
+	require.Len(t, orgErrs, 1)
+	assert.Equal(t, templatesUUID, orgErrs[0].Organization.UUID)
+	assert.Equal(t, boom, orgErrs[0].Err)
+}
+
+func TestForEachOrganization_Concurrency(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		orgs := make([]string, 10)
+		for i := range orgs {
+			orgs[i] = fmt.Sprintf(`{"name": "org-%d", "uuid": "uuid-%d"}`, i, i)
+		}
+		fmt.Fprintf(w, `{"access_token": "token", "expires_at": 9999999999, "organizations": [%s]}`, strings.Join(orgs, ","))
+	})
+
+	client, err := codeship.New(codeship.NewBasicAuth("test", "pass"), codeship.BaseURL(server.URL))
+	require.NoError(t, err)
+
+	var running, max, calls int32
+	err = client.ForEachOrganization(context.Background(), func(ctx context.Context, org *codeship.Organization) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		atomic.AddInt32(&calls, 1)
+
+		for {
+			m := atomic.LoadInt32(&max)
+			if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+				break
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), calls)
+	assert.True(t, max > 1, "expected organizations to be called concurrently")
+	assert.True(t, max <= 4, "expected at most 4 organizations at a time, got %d", max)
+}
+
+func TestListProjectsByOrganization(t *testing.T) {
+	mux, client, teardown := setupOrganizations(t)
+	defer teardown()
+
+	mux.HandleFunc("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, fixture("projects/list.json"))
+	})
+	mux.HandleFunc(fmt.Sprintf("/organizations/%s/projects", templatesUUID), func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"errors": ["forbidden"]}`)
+	})
+
+	results, err := client.ListProjectsByOrganization(context.Background())
+	require.Len(t, results, 1)
+	assert.Equal(t, "codeship", results[0].Organization.Name)
+	assert.Len(t, results[0].Projects, 2)
+
+	require.Error(t, err)
+	orgErrs, ok := err.(codeship.OrganizationErrors)
+	require.True(t, ok)
+	require.Len(t, orgErrs, 1)
+	assert.Equal(t, "templates", orgErrs[0].Organization.Name)
+}
+
+func TestListRunningBuilds(t *testing.T) {
+	mux, client, teardown := setupOrganizations(t)
+	defer teardown()
+
+	for _, uuid := range []string{"28123f10-e33d-5533-b53f-111ef8d7b14f", templatesUUID} {
+		mux.HandleFunc(fmt.Sprintf("/organizations/%s/projects", uuid), func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, fixture("projects/list.json"))
+		})
+		mux.HandleFunc(fmt.Sprintf("/organizations/%s/projects/", uuid), func(w http.ResponseWriter, r *http.Request) {
+			assert.True(t, strings.HasSuffix(r.URL.Path, "/builds"))
+			w.Header().Set("Content-Type", "application/json")
+
+			builds := fixture("builds/list.json")
+			if strings.Contains(r.URL.Path, "83605ef0-76f8-0135-8810-6e5f001a2e3c") {
+				builds = strings.Replace(builds, `"status": "success"`, `"status": "testing"`, 1)
+			}
+			fmt.Fprint(w, builds)
+		})
+	}
+
+	results, err := client.ListRunningBuilds(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	for i, org := range []string{"codeship", "templates"} {
+		assert.Equal(t, org, results[i].Organization.Name)
+		require.Len(t, results[i].Builds, 1)
+		assert.Equal(t, codeship.BuildStatusTesting, results[i].Builds[0].Status)
+	}
+}
+
+func TestListProjectsByOrganization_ExpiringToken(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// Tokens expire immediately, so every request authenticates again
+	var tokens int32
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		token := atomic.AddInt32(&tokens, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, strings.Replace(strings.Replace(fixture("auth/multiple_organizations.json"),
+			`"expires_at": 9999999999`, `"expires_at": 1`, 1),
+			`"access_token": "token"`, fmt.Sprintf(`"access_token": "token-%d"`, token), 1))
+	})
+	for _, uuid := range []string{"28123f10-e33d-5533-b53f-111ef8d7b14f", templatesUUID} {
+		mux.HandleFunc(fmt.Sprintf("/organizations/%s/projects", uuid), func(w http.ResponseWriter, r *http.Request) {
+			assert.Regexp(t, `^Bearer token-\d+$`, r.Header.Get("Authorization"))
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, fixture("projects/list.json"))
+		})
+	}
+
+	client, err := codeship.New(codeship.NewBasicAuth("test", "pass"), codeship.BaseURL(server.URL))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results, err := client.ListProjectsByOrganization(context.Background())
+			assert.NoError(t, err)
+			assert.Len(t, results, 2)
+		}()
+	}
+	wg.Wait()
+}
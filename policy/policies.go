@@ -0,0 +1,119 @@
+package policy
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	codeship "github.com/codeship/codeship-go"
+)
+
+// Defaults returns the built-in policies
+func Defaults() []Policy {
+	return []Policy{
+		FailureNotification,
+		NoSecretEnvironmentVariables,
+		NoPipeToShell,
+		SSHRepositoryURL,
+	}
+}
+
+// FailureNotification requires a notification rule for failed builds on all
+// branches
+var FailureNotification = Policy{
+	ID:          "failure-notification",
+	Description: "Every project has a notification rule for failed builds",
+	Severity:    SeverityError,
+	Check: func(p codeship.Project) []Finding {
+		for _, rule := range p.NotificationRules {
+			if rule.Branch != "" {
+				continue
+			}
+			for _, status := range rule.BuildStatuses {
+				if status == codeship.NotifyOnFailed {
+					return nil
+				}
+			}
+		}
+		return []Finding{{
+			Field:   "notification_rules",
+			Message: "no notification rule for failed builds on all branches",
+		}}
+	},
+}
+
+var secretName = regexp.MustCompile(`(?i)(SECRET|PASSWORD|PASSWD|TOKEN|API_?KEY|ACCESS_?KEY|PRIVATE_?KEY|CREDENTIAL)`)
+
+// NoSecretEnvironmentVariables forbids environment variables whose name looks
+// like a secret, which should be stored in an encrypted environment file
+// instead of the project settings
+var NoSecretEnvironmentVariables = Policy{
+	ID:          "secret-environment-variable",
+	Description: "Environment variables whose name looks like a secret are stored encrypted",
+	Severity:    SeverityError,
+	Check: func(p codeship.Project) []Finding {
+		var findings []Finding
+		for _, env := range p.EnvironmentVariables {
+			if secretName.MatchString(env.Name) {
+				findings = append(findings, Finding{
+					Field:   "environment_variables[" + env.Name + "]",
+					Message: env.Name + " looks like a secret and should be stored in an encrypted environment file",
+				})
+			}
+		}
+		return findings
+	},
+}
+
+var pipeToShell = regexp.MustCompile(`\b(curl|wget)\b[^|;&]*\|\s*(sudo\s+)?(\S*/)?(ba|z|da|k)?sh\b`)
+
+// NoPipeToShell forbids setup and test commands that pipe a download to a
+// shell, such as curl https://example.com/install.sh | bash
+var NoPipeToShell = Policy{
+	ID:          "pipe-to-shell",
+	Description: "Commands do not pipe a download to a shell",
+	Severity:    SeverityWarning,
+	Check: func(p codeship.Project) []Finding {
+		// Commands are not included as they may contain credentials
+		var findings []Finding
+		check := func(field string, commands []string) {
+			for i, cmd := range commands {
+				if pipeToShell.MatchString(cmd) {
+					findings = append(findings, Finding{
+						Field:   field,
+						Message: fmt.Sprintf("command %d pipes a download to a shell", i+1),
+					})
+				}
+			}
+		}
+		check("setup_commands", p.SetupCommands)
+		for _, tp := range p.TestPipelines {
+			check("test_pipelines["+tp.Name+"]", tp.Commands)
+		}
+		return findings
+	},
+}
+
+// SSHRepositoryURL requires repositories to be cloned over SSH
+var SSHRepositoryURL = Policy{
+	ID:          "ssh-repository-url",
+	Description: "Repository URLs use SSH",
+	Severity:    SeverityWarning,
+	Check: func(p codeship.Project) []Finding {
+		// scp-like URLs, e.g. git@github.com:org/repo.git, have no scheme
+		if strings.HasPrefix(p.RepositoryURL, "ssh://") || (strings.Contains(p.RepositoryURL, "@") && !strings.Contains(p.RepositoryURL, "://")) {
+			return nil
+		}
+
+		// The URL is not included as it may contain credentials
+		message := "repository URL does not use SSH"
+		if u, err := url.Parse(p.RepositoryURL); err == nil && u.Scheme != "" {
+			message = "repository URL uses " + u.Scheme + " instead of SSH"
+		}
+		return []Finding{{
+			Field:   "repository_url",
+			Message: message,
+		}}
+	},
+}
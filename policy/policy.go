@@ -0,0 +1,131 @@
+// Package policy checks projects against policies, such as requiring a
+// notification rule for failed builds, and reports the violations as text,
+// JSON or SARIF.
+package policy
+
+import (
+	"context"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/pkg/errors"
+)
+
+// Severity is how serious a violation of a policy is
+type Severity int
+
+const (
+	// SeverityError is a violation that should fail a check
+	SeverityError Severity = iota
+	// SeverityWarning is a violation that should be reviewed
+	SeverityWarning
+	// SeverityNote is informational
+	SeverityNote
+)
+
+var _severityValueToName = map[Severity]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityNote:    "note",
+}
+
+func (s Severity) String() string {
+	return _severityValueToName[s]
+}
+
+// MarshalText encodes the severity as its name
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Finding is a single problem found by a policy in a project
+type Finding struct {
+	// Field is the JSON name of the project field, e.g. setup_commands, with
+	// the key of the element if the field is a list, e.g.
+	// environment_variables[AWS_SECRET_ACCESS_KEY]
+	Field   string
+	Message string
+}
+
+// Policy is a check run against every project. Policies are pluggable: any
+// Policy with an ID and a Check can be passed to Evaluate.
+type Policy struct {
+	// ID identifies the policy in reports, e.g. ssh-repository-url
+	ID          string
+	Description string
+	Severity    Severity
+	// Check returns the findings for a project, or none if the project
+	// complies with the policy
+	Check func(p codeship.Project) []Finding
+}
+
+// Violation is a finding of a policy in a project
+type Violation struct {
+	Policy      string   `json:"policy"`
+	Severity    Severity `json:"severity"`
+	ProjectUUID string   `json:"project_uuid"`
+	ProjectName string   `json:"project_name"`
+	Field       string   `json:"field,omitempty"`
+	Message     string   `json:"message"`
+}
+
+// Report is the result of evaluating projects against policies
+type Report struct {
+	Policies   []Policy
+	Projects   []codeship.Project
+	Violations []Violation
+}
+
+// HasErrors reports whether any violation has SeverityError
+func (r Report) HasErrors() bool {
+	for _, v := range r.Violations {
+		if v.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate checks every project against every policy. Violations are ordered
+// by project, then by policy. If no policies are given, Defaults are used.
+func Evaluate(projects []codeship.Project, policies ...Policy) Report {
+	if len(policies) == 0 {
+		policies = Defaults()
+	}
+
+	report := Report{
+		Policies: policies,
+		Projects: projects,
+	}
+	for _, p := range projects {
+		for _, policy := range policies {
+			for _, f := range policy.Check(p) {
+				report.Violations = append(report.Violations, Violation{
+					Policy:      policy.ID,
+					Severity:    policy.Severity,
+					ProjectUUID: p.UUID,
+					ProjectName: p.Name,
+					Field:       f.Field,
+					Message:     f.Message,
+				})
+			}
+		}
+	}
+	return report
+}
+
+// Lister is the subset of *codeship.Organization used to lint an organization
+type Lister interface {
+	ListAllProjects(ctx context.Context) ([]codeship.Project, error)
+}
+
+var _ Lister = &codeship.Organization{}
+
+// Lint evaluates every project of an organization against the policies
+func Lint(ctx context.Context, client Lister, policies ...Policy) (Report, error) {
+	projects, err := client.ListAllProjects(ctx)
+	if err != nil {
+		return Report{}, errors.Wrap(err, "unable to lint projects")
+	}
+
+	return Evaluate(projects, policies...), nil
+}
@@ -0,0 +1,90 @@
+package policy_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLister struct {
+	projects []codeship.Project
+	err      error
+}
+
+func (l fakeLister) ListAllProjects(ctx context.Context) ([]codeship.Project, error) {
+	return l.projects, l.err
+}
+
+var projects = []codeship.Project{
+	{
+		Name:          "org/compliant",
+		RepositoryURL: "git@github.com:org/compliant.git",
+		NotificationRules: []codeship.NotificationRule{
+			codeship.EmailNotification(codeship.NotificationTargetCommitter, codeship.NotifyOnFailed),
+		},
+		UUID: "0059df30-7701-0135-8810-6e5f001a2e3c",
+	},
+	{
+		Name:          "org/legacy",
+		RepositoryURL: "https://github.com/org/legacy",
+		EnvironmentVariables: []codeship.EnvironmentVariable{
+			{Name: "API_TOKEN", Value: "secret"},
+		},
+		SetupCommands: []string{"curl -sSL https://example.com/install.sh | bash"},
+		UUID:          "c38f3280-2d13-0134-d0b4-1e1b0ba06dd1",
+	},
+}
+
+func TestEvaluate(t *testing.T) {
+	report := policy.Evaluate(projects)
+
+	assert.Len(t, report.Policies, 4)
+	assert.Equal(t, []policy.Violation{
+		{Policy: "failure-notification", Severity: policy.SeverityError, ProjectUUID: "c38f3280-2d13-0134-d0b4-1e1b0ba06dd1", ProjectName: "org/legacy", Field: "notification_rules", Message: "no notification rule for failed builds on all branches"},
+		{Policy: "secret-environment-variable", Severity: policy.SeverityError, ProjectUUID: "c38f3280-2d13-0134-d0b4-1e1b0ba06dd1", ProjectName: "org/legacy", Field: "environment_variables[API_TOKEN]", Message: "API_TOKEN looks like a secret and should be stored in an encrypted environment file"},
+		{Policy: "pipe-to-shell", Severity: policy.SeverityWarning, ProjectUUID: "c38f3280-2d13-0134-d0b4-1e1b0ba06dd1", ProjectName: "org/legacy", Field: "setup_commands", Message: "command 1 pipes a download to a shell"},
+		{Policy: "ssh-repository-url", Severity: policy.SeverityWarning, ProjectUUID: "c38f3280-2d13-0134-d0b4-1e1b0ba06dd1", ProjectName: "org/legacy", Field: "repository_url", Message: "repository URL uses https instead of SSH"},
+	}, report.Violations)
+	assert.True(t, report.HasErrors())
+}
+
+func TestEvaluate_CustomPolicy(t *testing.T) {
+	pro := policy.Policy{
+		ID:       "pro-projects",
+		Severity: policy.SeverityNote,
+		Check: func(p codeship.Project) []policy.Finding {
+			if p.Type != codeship.ProjectTypePro {
+				return []policy.Finding{{Field: "type", Message: "project is not a pro project"}}
+			}
+			return nil
+		},
+	}
+
+	report := policy.Evaluate(projects[:1], pro)
+
+	require.Len(t, report.Violations, 1)
+	assert.Equal(t, "pro-projects", report.Violations[0].Policy)
+	assert.Equal(t, policy.SeverityNote, report.Violations[0].Severity)
+	assert.False(t, report.HasErrors())
+}
+
+func TestLint(t *testing.T) {
+	report, err := policy.Lint(context.Background(), fakeLister{projects: projects}, policy.SSHRepositoryURL)
+	require.NoError(t, err)
+	assert.Len(t, report.Projects, 2)
+	assert.Len(t, report.Violations, 1)
+
+	_, err = policy.Lint(context.Background(), fakeLister{err: errors.New("boom")})
+	assert.EqualError(t, err, "unable to lint projects: boom")
+}
+
+func TestSeverity_String(t *testing.T) {
+	assert.Equal(t, "error", policy.SeverityError.String())
+	assert.Equal(t, "warning", policy.SeverityWarning.String())
+	assert.Equal(t, "note", policy.SeverityNote.String())
+}
@@ -0,0 +1,214 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Format is the output format of a report
+type Format int
+
+const (
+	// FormatText is a human readable list of violations grouped by project
+	FormatText Format = iota
+	// FormatJSON is a JSON object with the list of violations
+	FormatJSON
+	// FormatSARIF is a SARIF 2.1.0 log, which can be uploaded to code
+	// scanning tools
+	FormatSARIF
+)
+
+var _formatValueToName = map[Format]string{
+	FormatText:  "text",
+	FormatJSON:  "json",
+	FormatSARIF: "sarif",
+}
+
+func (f Format) String() string {
+	return _formatValueToName[f]
+}
+
+// ParseFormat returns the format with the given name, e.g. from a command
+// line flag
+func ParseFormat(name string) (Format, error) {
+	for f, n := range _formatValueToName {
+		if strings.EqualFold(n, name) {
+			return f, nil
+		}
+	}
+	return FormatText, errors.Errorf("unsupported report format %q", name)
+}
+
+// Write writes the report to w in the given format
+func (r Report) Write(w io.Writer, format Format) error {
+	var buf bytes.Buffer
+	switch format {
+	case FormatText:
+		r.writeText(&buf)
+	case FormatJSON:
+		if err := r.writeJSON(&buf); err != nil {
+			return err
+		}
+	case FormatSARIF:
+		if err := r.writeSARIF(&buf); err != nil {
+			return err
+		}
+	default:
+		return errors.Errorf("unsupported report format %d", format)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (r Report) String() string {
+	var buf bytes.Buffer
+	r.writeText(&buf)
+	return buf.String()
+}
+
+func (r Report) writeText(buf *bytes.Buffer) {
+	failing := 0
+	for i, v := range r.Violations {
+		if i == 0 || v.ProjectUUID != r.Violations[i-1].ProjectUUID {
+			failing++
+			fmt.Fprintf(buf, "%s (%s)\n", v.ProjectName, v.ProjectUUID)
+		}
+		fmt.Fprintf(buf, "  %-7s %s: %s", v.Severity, v.Policy, v.Message)
+		if v.Field != "" {
+			fmt.Fprintf(buf, " (%s)", v.Field)
+		}
+		buf.WriteString("\n")
+	}
+
+	if len(r.Violations) > 0 {
+		buf.WriteString("\n")
+	}
+	fmt.Fprintf(buf, "%d violations in %d of %d projects\n", len(r.Violations), failing, len(r.Projects))
+}
+
+type jsonReport struct {
+	Projects   int         `json:"projects"`
+	Violations []Violation `json:"violations"`
+}
+
+func (r Report) writeJSON(buf *bytes.Buffer) error {
+	report := jsonReport{
+		Projects:   len(r.Projects),
+		Violations: r.Violations,
+	}
+	if report.Violations == nil {
+		report.Violations = []Violation{}
+	}
+
+	enc := json.NewEncoder(buf)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(report), "unable to encode report")
+}
+
+// sarifLog is the subset of the SARIF 2.1.0 format used for reports
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level Severity `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     Severity        `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+func (r Report) writeSARIF(buf *bytes.Buffer) error {
+	driver := sarifDriver{
+		Name:           "codeship-go policy",
+		InformationURI: "https://github.com/codeship/codeship-go",
+		Rules:          []sarifRule{},
+	}
+	index := make(map[string]int, len(r.Policies))
+	for i, p := range r.Policies {
+		index[p.ID] = i
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   p.ID,
+			ShortDescription:     sarifMessage{Text: p.Description},
+			DefaultConfiguration: sarifConfiguration{Level: p.Severity},
+		})
+	}
+
+	run := sarifRun{
+		Tool:    sarifTool{Driver: driver},
+		Results: []sarifResult{},
+	}
+	for _, v := range r.Violations {
+		name := v.ProjectName
+		fqn := "projects/" + v.ProjectUUID
+		if v.Field != "" {
+			name = v.Field
+			fqn += "/" + v.Field
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    v.Policy,
+			RuleIndex: index[v.Policy],
+			Level:     v.Severity,
+			Message:   sarifMessage{Text: v.ProjectName + ": " + v.Message},
+			Locations: []sarifLocation{{
+				LogicalLocations: []sarifLogicalLocation{{Name: name, FullyQualifiedName: fqn, Kind: "resource"}},
+			}},
+		})
+	}
+
+	enc := json.NewEncoder(buf)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+	return errors.Wrap(err, "unable to encode report")
+}
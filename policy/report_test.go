@@ -0,0 +1,93 @@
+package policy_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/codeship/codeship-go/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestReport_Write(t *testing.T) {
+	report := policy.Evaluate(projects)
+
+	tests := []struct {
+		name   string
+		format policy.Format
+		golden string
+	}{
+		{
+			name:   "text",
+			format: policy.FormatText,
+			golden: "testdata/report.txt",
+		},
+		{
+			name:   "json",
+			format: policy.FormatJSON,
+			golden: "testdata/report.json",
+		},
+		{
+			name:   "sarif",
+			format: policy.FormatSARIF,
+			golden: "testdata/report.sarif",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, report.Write(&buf, tt.format))
+
+			if *update {
+				require.NoError(t, ioutil.WriteFile(tt.golden, buf.Bytes(), 0644))
+			}
+
+			want, err := ioutil.ReadFile(tt.golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), buf.String())
+
+			if tt.format != policy.FormatText {
+				assert.True(t, json.Valid(buf.Bytes()))
+			}
+		})
+	}
+}
+
+func TestReport_WriteEmpty(t *testing.T) {
+	report := policy.Evaluate(projects[:1])
+
+	assert.Equal(t, "0 violations in 0 of 1 projects\n", report.String())
+
+	var buf bytes.Buffer
+	require.NoError(t, report.Write(&buf, policy.FormatJSON))
+	assert.JSONEq(t, `{"projects": 1, "violations": []}`, buf.String())
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format policy.Format
+		err    string
+	}{
+		{name: "text", format: policy.FormatText},
+		{name: "json", format: policy.FormatJSON},
+		{name: "SARIF", format: policy.FormatSARIF},
+		{name: "xml", err: `unsupported report format "xml"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := policy.ParseFormat(tt.name)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.format, format)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package projectconfig
+
+import (
+	"context"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/pkg/errors"
+)
+
+// ApplyOptions configures Apply
+type ApplyOptions struct {
+	// DryRun reports what would be applied without making any changes
+	DryRun bool
+}
+
+// Result summarizes the changes made by Apply
+type Result struct {
+	Created   []codeship.Project
+	Updated   []codeship.Project
+	Unchanged int
+}
+
+// Apply creates and updates projects according to the plan, only sending the
+// fields that changed. It stops at the first error, returning the changes
+// made so far. Applying a plan made after a successful Apply is a no-op.
+func Apply(ctx context.Context, client Client, plan Plan, opts ApplyOptions) (Result, error) {
+	var result Result
+
+	for _, pp := range plan.Projects {
+		switch pp.Action {
+		case ActionCreate:
+			if opts.DryRun {
+				result.Created = append(result.Created, codeship.Project{RepositoryURL: pp.Desired.RepositoryURL, Type: *pp.Desired.Type})
+				continue
+			}
+
+			project, _, err := client.CreateProject(ctx, createRequest(pp.Desired))
+			if err != nil {
+				return result, errors.Wrapf(err, "unable to apply project %s", pp.Desired)
+			}
+			result.Created = append(result.Created, project)
+		case ActionUpdate:
+			if opts.DryRun {
+				result.Updated = append(result.Updated, *pp.Current)
+				continue
+			}
+
+			project, _, err := client.UpdateProject(ctx, pp.Current.UUID, updateRequest(pp))
+			if err != nil {
+				return result, errors.Wrapf(err, "unable to apply project %s", pp.Desired)
+			}
+			result.Updated = append(result.Updated, project)
+		default:
+			result.Unchanged++
+		}
+	}
+
+	return result, nil
+}
+
+func createRequest(p Project) codeship.ProjectCreateRequest {
+	return codeship.ProjectCreateRequest{
+		EnvironmentVariables: p.EnvironmentVariables,
+		NotificationRules:    p.NotificationRules,
+		RepositoryURL:        p.RepositoryURL,
+		SetupCommands:        p.SetupCommands,
+		TeamIDs:              p.TeamIDs,
+		TestPipelines:        p.TestPipelines,
+		Type:                 *p.Type,
+	}
+}
+
+// updateRequest only includes the fields with changes. Type is always sent as
+// it is required by the API.
+func updateRequest(pp ProjectPlan) codeship.ProjectUpdateRequest {
+	req := codeship.ProjectUpdateRequest{
+		Type: pp.Current.Type,
+	}
+
+	for _, c := range pp.Changes {
+		switch c.Field {
+		case "type":
+			req.Type = *pp.Desired.Type
+		case "team_ids":
+			req.TeamIDs = pp.Desired.TeamIDs
+		case "setup_commands":
+			req.SetupCommands = pp.Desired.SetupCommands
+		case "environment_variables":
+			req.EnvironmentVariables = pp.Desired.EnvironmentVariables
+		case "notification_rules":
+			req.NotificationRules = pp.Desired.NotificationRules
+		}
+	}
+
+	return req
+}
@@ -0,0 +1,110 @@
+package projectconfig_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/projectconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := projectconfig.Load("testdata/projects.yml")
+	require.NoError(t, err)
+
+	client := newFakeClient()
+
+	plan, err := projectconfig.MakePlan(ctx, client, c)
+	require.NoError(t, err)
+
+	result, err := projectconfig.Apply(ctx, client, plan, projectconfig.ApplyOptions{})
+	require.NoError(t, err)
+	require.Len(t, result.Created, 1)
+	require.Len(t, result.Updated, 1)
+	assert.Equal(t, 0, result.Unchanged)
+
+	require.Len(t, client.Updates, 1)
+	assert.Equal(t, codeship.ProjectUpdateRequest{
+		Type:                 codeship.ProjectTypePro,
+		TeamIDs:              c.Projects[0].TeamIDs,
+		SetupCommands:        c.Projects[0].SetupCommands,
+		EnvironmentVariables: c.Projects[0].EnvironmentVariables,
+		NotificationRules:    c.Projects[0].NotificationRules,
+	}, client.Updates[0])
+	assert.Equal(t, "https://github.com/org/new-project", result.Created[0].RepositoryURL)
+
+	// applying again is a no-op
+	plan, err = projectconfig.MakePlan(ctx, client, c)
+	require.NoError(t, err)
+	assert.False(t, plan.HasChanges(), plan.String())
+
+	result, err = projectconfig.Apply(ctx, client, plan, projectconfig.ApplyOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Unchanged)
+	assert.Len(t, client.Updates, 1)
+	assert.Equal(t, 1, client.Creates)
+}
+
+func TestApply_OnlyChangedFields(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := projectconfig.Parse([]byte("projects:\n  - uuid: 0059df30-7701-0135-8810-6e5f001a2e3c\n    team_ids: [1007, 2000]\n    setup_commands: []\n"))
+	require.NoError(t, err)
+
+	client := newFakeClient()
+
+	plan, err := projectconfig.MakePlan(ctx, client, c)
+	require.NoError(t, err)
+
+	_, err = projectconfig.Apply(ctx, client, plan, projectconfig.ApplyOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, client.Updates, 1)
+	assert.Equal(t, codeship.ProjectUpdateRequest{
+		Type:    codeship.ProjectTypePro,
+		TeamIDs: []int{1007, 2000},
+	}, client.Updates[0])
+}
+
+func TestApply_DryRun(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := projectconfig.Load("testdata/projects.yml")
+	require.NoError(t, err)
+
+	client := newFakeClient()
+
+	plan, err := projectconfig.MakePlan(ctx, client, c)
+	require.NoError(t, err)
+
+	result, err := projectconfig.Apply(ctx, client, plan, projectconfig.ApplyOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.Len(t, result.Created, 1)
+	assert.Len(t, result.Updated, 1)
+
+	assert.Equal(t, 0, client.Creates)
+	assert.Empty(t, client.Updates)
+}
+
+func TestApply_Error(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := projectconfig.Load("testdata/projects.yml")
+	require.NoError(t, err)
+
+	client := newFakeClient()
+
+	plan, err := projectconfig.MakePlan(ctx, client, c)
+	require.NoError(t, err)
+
+	client.Err = errors.New("boom")
+	result, err := projectconfig.Apply(ctx, client, plan, projectconfig.ApplyOptions{})
+	assert.EqualError(t, err, "unable to apply project 0059df30-7701-0135-8810-6e5f001a2e3c: boom")
+	assert.Empty(t, result.Updated)
+	assert.Empty(t, result.Created)
+}
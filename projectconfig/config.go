@@ -0,0 +1,108 @@
+// Package projectconfig manages Codeship projects declaratively. A
+// configuration file describes the desired state of projects, which is
+// compared with their current state to produce a Plan that can be reviewed and
+// then applied.
+package projectconfig
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the desired state of a set of projects
+type Config struct {
+	Projects []Project `json:"projects"`
+}
+
+// Project is the desired state of a single project. Fields that are omitted
+// are not managed and are left unchanged. Fields that are set, including to an
+// empty list, replace the current value.
+type Project struct {
+	// UUID identifies an existing project. If empty, the project is matched
+	// by RepositoryURL and created if it does not exist.
+	UUID                 string                         `json:"uuid,omitempty"`
+	RepositoryURL        string                         `json:"repository_url,omitempty"`
+	Type                 *codeship.ProjectType          `json:"type,omitempty"`
+	TeamIDs              []int                          `json:"team_ids"`
+	SetupCommands        []string                       `json:"setup_commands"`
+	EnvironmentVariables []codeship.EnvironmentVariable `json:"environment_variables"`
+	NotificationRules    []codeship.NotificationRule    `json:"notification_rules"`
+	// TestPipelines are only applied when a project is created, as they
+	// cannot be updated through the API
+	TestPipelines []codeship.TestPipeline `json:"test_pipelines"`
+}
+
+// String returns the UUID or repository URL identifying the project
+func (p Project) String() string {
+	if p.UUID != "" {
+		return p.UUID
+	}
+	return p.RepositoryURL
+}
+
+// Parse parses a YAML or JSON configuration. Keys match the JSON fields of the
+// codeship types, e.g. environment_variables and build_statuses.
+func Parse(data []byte) (Config, error) {
+	// YAML is decoded generically and converted to JSON so that the json tags
+	// of the codeship types are used for both formats
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return Config{}, errors.Wrap(err, "unable to parse project configuration")
+	}
+
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return Config{}, errors.Wrap(err, "unable to parse project configuration")
+	}
+
+	var c Config
+	if err := json.Unmarshal(b, &c); err != nil {
+		return Config{}, errors.Wrap(err, "unable to parse project configuration")
+	}
+
+	if err := c.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return c, nil
+}
+
+// Load reads and parses a YAML or JSON configuration file
+func Load(path string) (Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, errors.Wrap(err, "unable to read project configuration")
+	}
+	return Parse(b)
+}
+
+// Validate checks that every project can be identified and that its
+// notification rules are valid
+func (c Config) Validate() error {
+	seen := make(map[string]bool, len(c.Projects))
+	for i, p := range c.Projects {
+		if p.UUID == "" && p.RepositoryURL == "" {
+			return errors.Errorf("invalid project configuration: projects[%d] requires a uuid or repository_url", i)
+		}
+
+		key := p.UUID
+		if key == "" {
+			key = codeship.NormalizeRepositoryURL(p.RepositoryURL)
+		}
+		if seen[key] {
+			return errors.Errorf("invalid project configuration: project %s is defined more than once", p)
+		}
+		seen[key] = true
+
+		for j, rule := range p.NotificationRules {
+			if err := rule.Validate(); err != nil {
+				return errors.Wrapf(err, "invalid project configuration: project %s notification_rules[%d]", p, j)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package projectconfig_test
+
+import (
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/projectconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	c, err := projectconfig.Load("testdata/projects.yml")
+	require.NoError(t, err)
+	require.Len(t, c.Projects, 2)
+
+	existing := c.Projects[0]
+	assert.Equal(t, "0059df30-7701-0135-8810-6e5f001a2e3c", existing.String())
+	assert.Nil(t, existing.Type)
+	assert.Equal(t, []int{1007, 2000}, existing.TeamIDs)
+	assert.Equal(t, []codeship.EnvironmentVariable{{Name: "DATABASE_URL", Value: "postgres://localhost/test"}}, existing.EnvironmentVariables)
+	require.Len(t, existing.NotificationRules, 2)
+	assert.Equal(t, []codeship.NotifyOn{codeship.NotifyOnFailed}, existing.NotificationRules[1].BuildStatuses)
+	assert.Equal(t, "https://hooks.slack.com/services/T000/B000/XXX", existing.NotificationRules[1].Options.URL)
+	assert.Nil(t, existing.TestPipelines)
+
+	created := c.Projects[1]
+	assert.Equal(t, "https://github.com/org/new-project", created.String())
+	require.NotNil(t, created.Type)
+	assert.Equal(t, codeship.ProjectTypeBasic, *created.Type)
+	assert.Equal(t, []codeship.TestPipeline{{Name: "tests", Commands: []string{"go test ./..."}}}, created.TestPipelines)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			name: "json",
+			data: `{"projects": [{"uuid": "0059df30-7701-0135-8810-6e5f001a2e3c", "setup_commands": []}]}`,
+		},
+		{
+			name: "invalid yaml",
+			data: "projects: [",
+			err:  "unable to parse project configuration",
+		},
+		{
+			name: "invalid type",
+			data: "projects:\n  - repository_url: https://github.com/org/repo\n    type: premium\n",
+			err:  "unable to parse project configuration: invalid ProjectType: premium",
+		},
+		{
+			name: "missing identifier",
+			data: "projects:\n  - team_ids: [1]\n",
+			err:  "invalid project configuration: projects[0] requires a uuid or repository_url",
+		},
+		{
+			name: "duplicate project",
+			data: "projects:\n  - uuid: abc\n  - uuid: abc\n",
+			err:  "invalid project configuration: project abc is defined more than once",
+		},
+		{
+			name: "duplicate repository in another form",
+			data: "projects:\n  - repository_url: https://github.com/org/repo\n  - repository_url: git@github.com:org/repo.git\n",
+			err:  "invalid project configuration: project git@github.com:org/repo.git is defined more than once",
+		},
+		{
+			name: "invalid notification rule",
+			data: "projects:\n  - uuid: abc\n    notification_rules:\n      - notifier: slack\n        build_statuses: [failed]\n",
+			err:  "invalid project configuration: project abc notification_rules[0]: options.url is required for slack notifications",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := projectconfig.Parse([]byte(tt.data))
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, c.Projects, 1)
+			assert.NotNil(t, c.Projects[0].SetupCommands, "expected an empty list to be kept as managed")
+			assert.Nil(t, c.Projects[0].TeamIDs, "expected an omitted list to be unmanaged")
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package projectconfig
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/pkg/errors"
+)
+
+// Client is the subset of *codeship.Organization used to plan and apply changes
+type Client interface {
+	ListAllProjects(ctx context.Context) ([]codeship.Project, error)
+	GetProject(ctx context.Context, projectUUID string) (codeship.Project, codeship.Response, error)
+	CreateProject(ctx context.Context, p codeship.ProjectCreateRequest) (codeship.Project, codeship.Response, error)
+	UpdateProject(ctx context.Context, projectUUID string, p codeship.ProjectUpdateRequest) (codeship.Project, codeship.Response, error)
+}
+
+var _ Client = &codeship.Organization{}
+
+// Action is what applying a plan does to a project
+type Action int
+
+const (
+	// ActionNone leaves a project that is already in the desired state unchanged
+	ActionNone Action = iota
+	// ActionCreate creates a project that does not exist
+	ActionCreate
+	// ActionUpdate updates a project that differs from the desired state
+	ActionUpdate
+)
+
+var _actionValueToName = map[Action]string{
+	ActionNone:   "unchanged",
+	ActionCreate: "create",
+	ActionUpdate: "update",
+}
+
+func (a Action) String() string {
+	return _actionValueToName[a]
+}
+
+// ProjectPlan is the planned action for a single project
+type ProjectPlan struct {
+	Desired Project
+	// Current is the project as returned by the API, or nil if it will be created
+	Current  *codeship.Project
+	Action   Action
+	Changes  []codeship.Change
+	Warnings []string
+}
+
+// Plan is the set of actions needed to bring projects to their desired state
+type Plan struct {
+	Projects []ProjectPlan
+}
+
+// HasChanges reports whether applying the plan would create or update any project
+func (p Plan) HasChanges() bool {
+	for _, pp := range p.Projects {
+		if pp.Action != ActionNone {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteTo writes a human readable description of the plan to w
+func (p Plan) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	counts := make(map[Action]int)
+
+	for _, pp := range p.Projects {
+		counts[pp.Action]++
+
+		switch pp.Action {
+		case ActionCreate:
+			fmt.Fprintf(&buf, "+ create %s\n", pp.Desired)
+		case ActionUpdate:
+			fmt.Fprintf(&buf, "~ update %s (%s)\n", pp.Current.Name, pp.Current.UUID)
+		default:
+			fmt.Fprintf(&buf, "  unchanged %s (%s)\n", pp.Current.Name, pp.Current.UUID)
+		}
+
+		for _, c := range pp.Changes {
+			fmt.Fprintf(&buf, "    %s\n", c)
+		}
+		for _, warning := range pp.Warnings {
+			fmt.Fprintf(&buf, "    ! %s\n", warning)
+		}
+	}
+
+	fmt.Fprintf(&buf, "\nPlan: %d to create, %d to update, %d unchanged.\n",
+		counts[ActionCreate], counts[ActionUpdate], counts[ActionNone])
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+func (p Plan) String() string {
+	var buf bytes.Buffer
+	_, _ = p.WriteTo(&buf)
+	return buf.String()
+}
+
+// MakePlan compares the desired state of each project in c with its current
+// state. Projects without a UUID are matched by repository URL, in any of the
+// forms accepted by codeship.NormalizeRepositoryURL.
+func MakePlan(ctx context.Context, client Client, c Config) (Plan, error) {
+	if err := c.Validate(); err != nil {
+		return Plan{}, err
+	}
+
+	var byURL map[string]codeship.Project
+
+	var plan Plan
+	for _, desired := range c.Projects {
+		var current *codeship.Project
+
+		if desired.UUID != "" {
+			project, _, err := client.GetProject(ctx, desired.UUID)
+			if err != nil {
+				return Plan{}, errors.Wrapf(err, "unable to plan project %s", desired)
+			}
+			current = &project
+		} else {
+			if byURL == nil {
+				projects, err := client.ListAllProjects(ctx)
+				if err != nil {
+					return Plan{}, errors.Wrap(err, "unable to plan projects")
+				}
+				byURL = make(map[string]codeship.Project, len(projects))
+				for _, project := range projects {
+					byURL[codeship.NormalizeRepositoryURL(project.RepositoryURL)] = project
+				}
+			}
+			if project, ok := byURL[codeship.NormalizeRepositoryURL(desired.RepositoryURL)]; ok {
+				current = &project
+			}
+		}
+
+		pp, err := planProject(desired, current)
+		if err != nil {
+			return Plan{}, err
+		}
+		plan.Projects = append(plan.Projects, pp)
+	}
+
+	return plan, nil
+}
+
+func planProject(desired Project, current *codeship.Project) (ProjectPlan, error) {
+	pp := ProjectPlan{
+		Desired: desired,
+		Current: current,
+	}
+
+	if current == nil {
+		if desired.Type == nil {
+			return ProjectPlan{}, errors.Errorf("unable to plan project %s: type is required to create a project", desired)
+		}
+		base := codeship.Project{Type: *desired.Type}
+		pp.Action = ActionCreate
+		pp.Changes = append(
+			[]codeship.Change{{Type: codeship.ChangeAdded, Field: "type", New: desired.Type.String()}},
+			codeship.DiffProjects(base, desired.apply(base))...,
+		)
+		return pp, nil
+	}
+
+	changes := codeship.DiffProjects(*current, desired.apply(*current))
+
+	// Test pipelines can only be set when a project is created
+	skip := map[string]bool{"test_pipelines": true}
+	for _, c := range changes {
+		if c.Field == "test_pipelines" {
+			pp.Warnings = append(pp.Warnings, "test_pipelines cannot be updated through the API and will not be changed")
+			break
+		}
+	}
+
+	// UpdateProject omits empty lists, so a field can not be cleared
+	cleared := func(field string, desired, current int) {
+		if desired == 0 && current > 0 {
+			skip[field] = true
+			pp.Warnings = append(pp.Warnings, field+" cannot be cleared through the API and will not be changed")
+		}
+	}
+	if desired.TeamIDs != nil {
+		cleared("team_ids", len(desired.TeamIDs), len(current.TeamIDs))
+	}
+	if desired.SetupCommands != nil {
+		cleared("setup_commands", len(desired.SetupCommands), len(current.SetupCommands))
+	}
+	if desired.EnvironmentVariables != nil {
+		cleared("environment_variables", len(desired.EnvironmentVariables), len(current.EnvironmentVariables))
+	}
+	if desired.NotificationRules != nil {
+		cleared("notification_rules", len(desired.NotificationRules), len(current.NotificationRules))
+	}
+
+	for _, c := range changes {
+		if !skip[c.Field] {
+			pp.Changes = append(pp.Changes, c)
+		}
+	}
+
+	if len(pp.Changes) > 0 {
+		pp.Action = ActionUpdate
+	}
+
+	return pp, nil
+}
+
+// apply returns project with the managed fields replaced by their desired values
+func (p Project) apply(project codeship.Project) codeship.Project {
+	if p.Type != nil {
+		project.Type = *p.Type
+	}
+	if p.TeamIDs != nil {
+		project.TeamIDs = p.TeamIDs
+	}
+	if p.SetupCommands != nil {
+		project.SetupCommands = p.SetupCommands
+	}
+	if p.EnvironmentVariables != nil {
+		project.EnvironmentVariables = p.EnvironmentVariables
+	}
+	if p.NotificationRules != nil {
+		project.NotificationRules = p.NotificationRules
+	}
+	if p.TestPipelines != nil {
+		project.TestPipelines = p.TestPipelines
+	}
+	return project
+}
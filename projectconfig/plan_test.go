@@ -0,0 +1,158 @@
+package projectconfig_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/internal/projecttest"
+	"github.com/codeship/codeship-go/projectconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFakeClient() *projecttest.Client {
+	return &projecttest.Client{
+		Projects: []codeship.Project{
+			{
+				UUID:          "0059df30-7701-0135-8810-6e5f001a2e3c",
+				Name:          "org/test-project",
+				RepositoryURL: "https://github.com/org/test-project",
+				Type:          codeship.ProjectTypePro,
+				TeamIDs:       []int{1007},
+				EnvironmentVariables: []codeship.EnvironmentVariable{
+					{Name: "DATABASE_URL", Value: "postgres://db/test"},
+					{Name: "OLD", Value: "value"},
+				},
+				NotificationRules: []codeship.NotificationRule{
+					{Notifier: "github", BranchMatch: "exact", BuildStatuses: []codeship.NotifyOn{"failed", "started", "recovered", "success"}, Target: "all"},
+					{Notifier: "email", BranchMatch: "exact", BuildStatuses: []codeship.NotifyOn{"failed", "recovered"}, Target: "all"},
+				},
+			},
+			{
+				UUID:          "c38f3280-2d13-0134-d0b4-1e1b0ba06dd1",
+				Name:          "org/other",
+				RepositoryURL: "https://github.com/org/other",
+				Type:          codeship.ProjectTypeBasic,
+				SetupCommands: []string{"./setup"},
+				TestPipelines: []codeship.TestPipeline{{ID: 1, Name: "tests", Commands: []string{"make test"}}},
+			},
+		},
+	}
+}
+
+func TestMakePlan(t *testing.T) {
+	c, err := projectconfig.Load("testdata/projects.yml")
+	require.NoError(t, err)
+
+	plan, err := projectconfig.MakePlan(context.Background(), newFakeClient(), c)
+	require.NoError(t, err)
+	require.Len(t, plan.Projects, 2)
+	assert.True(t, plan.HasChanges())
+
+	assert.Equal(t, projectconfig.ActionUpdate, plan.Projects[0].Action)
+	assert.Equal(t, projectconfig.ActionCreate, plan.Projects[1].Action)
+	assert.Nil(t, plan.Projects[1].Current)
+
+	assert.Equal(t, `~ update org/test-project (0059df30-7701-0135-8810-6e5f001a2e3c)
+    + team_ids: 2000
+    ~ setup_commands: [] -> ["./scripts/setup"]
+    - environment_variables[OLD]: [REDACTED]
+    ~ environment_variables[DATABASE_URL]: [REDACTED] -> [REDACTED]
+    - notification_rules[email on failed,recovered]: email on failed,recovered
+    + notification_rules[slack on failed]: slack on failed
++ create https://github.com/org/new-project
+    + type: basic
+    + team_ids: 1007
+    + test_pipelines[tests]: ["go test ./..."]
+
+Plan: 1 to create, 1 to update, 0 unchanged.
+`, plan.String())
+	assert.NotContains(t, plan.String(), "postgres://", "expected secrets to be masked")
+	assert.NotContains(t, plan.String(), "hooks.slack.com", "expected secrets to be masked")
+}
+
+func TestMakePlan_Warnings(t *testing.T) {
+	c, err := projectconfig.Parse([]byte(`
+projects:
+  - repository_url: https://github.com/org/other
+    setup_commands: []
+    test_pipelines:
+      - name: tests
+        commands: [go test ./...]
+`))
+	require.NoError(t, err)
+
+	plan, err := projectconfig.MakePlan(context.Background(), newFakeClient(), c)
+	require.NoError(t, err)
+	require.Len(t, plan.Projects, 1)
+
+	pp := plan.Projects[0]
+	assert.Equal(t, projectconfig.ActionNone, pp.Action)
+	assert.Empty(t, pp.Changes)
+	assert.Equal(t, []string{
+		"test_pipelines cannot be updated through the API and will not be changed",
+		"setup_commands cannot be cleared through the API and will not be changed",
+	}, pp.Warnings)
+	assert.False(t, plan.HasChanges())
+}
+
+func TestMakePlan_RepositoryURLForms(t *testing.T) {
+	for _, url := range []string{
+		"git@github.com:org/other.git",
+		"ssh://git@github.com/org/other",
+		"https://GitHub.com/org/other/",
+	} {
+		t.Run(url, func(t *testing.T) {
+			c, err := projectconfig.Parse([]byte("projects:\n  - repository_url: " + url + "\n"))
+			require.NoError(t, err)
+
+			plan, err := projectconfig.MakePlan(context.Background(), newFakeClient(), c)
+			require.NoError(t, err)
+			require.Len(t, plan.Projects, 1)
+
+			pp := plan.Projects[0]
+			assert.Equal(t, projectconfig.ActionNone, pp.Action)
+			require.NotNil(t, pp.Current)
+			assert.Equal(t, "c38f3280-2d13-0134-d0b4-1e1b0ba06dd1", pp.Current.UUID)
+		})
+	}
+}
+
+func TestMakePlan_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		client *projecttest.Client
+		err    string
+	}{
+		{
+			name:   "project not found",
+			data:   "projects:\n  - uuid: missing\n",
+			client: newFakeClient(),
+			err:    "unable to plan project missing: project not found",
+		},
+		{
+			name:   "list fails",
+			data:   "projects:\n  - repository_url: https://github.com/org/repo\n",
+			client: &projecttest.Client{Err: errors.New("boom")},
+			err:    "unable to plan projects: boom",
+		},
+		{
+			name:   "create without type",
+			data:   "projects:\n  - repository_url: https://github.com/org/repo\n",
+			client: newFakeClient(),
+			err:    "unable to plan project https://github.com/org/repo: type is required to create a project",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := projectconfig.Parse([]byte(tt.data))
+			require.NoError(t, err)
+
+			_, err = projectconfig.MakePlan(context.Background(), tt.client, c)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -84,9 +84,9 @@ type NotificationOptions struct {
 type NotificationRule struct {
 	Branch        string              `json:"branch,omitempty"`
 	BranchMatch   string              `json:"branch_match,omitempty"`
-	Notifier      string              `json:"notifier,omitempty"`
+	Notifier      Notifier            `json:"notifier,omitempty"`
 	Options       NotificationOptions `json:"options,omitempty"`
-	BuildStatuses []string            `json:"build_statuses,omitempty"`
+	BuildStatuses []NotifyOn          `json:"build_statuses,omitempty"`
 	Target        string              `json:"target,omitempty"`
 }
 
@@ -159,7 +159,7 @@ func (o *Organization) ListProjects(ctx context.Context, opts ...PaginationOptio
 		return ProjectList{}, Response{}, errors.Wrap(err, "unable to list projects")
 	}
 
-	body, resp, err := o.client.request(ctx, "GET", path, nil)
+	body, resp, err := o.request(ctx, "ListProjects", "GET", path, nil)
 	if err != nil {
 		return ProjectList{}, resp, errors.Wrap(err, "unable to list projects")
 	}
@@ -172,13 +172,30 @@ func (o *Organization) ListProjects(ctx context.Context, opts ...PaginationOptio
 	return projects, resp, nil
 }
 
+// ListAllProjects fetches the projects of every page
+func (o *Organization) ListAllProjects(ctx context.Context) ([]Project, error) {
+	var projects []Project
+	for page := 1; page != 0; {
+		list, resp, err := o.ListProjects(ctx, Page(page), PerPage(50))
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, list.Projects...)
+
+		if page, err = resp.NextPage(); err != nil {
+			return nil, errors.Wrap(err, "unable to list projects")
+		}
+	}
+	return projects, nil
+}
+
 // GetProject fetches a project by UUID
 //
 // Codeship API docs: https://apidocs.codeship.com/v2/projects/get-project
 func (o *Organization) GetProject(ctx context.Context, projectUUID string) (Project, Response, error) {
 	path := fmt.Sprintf("/organizations/%s/projects/%s", o.UUID, projectUUID)
 
-	body, resp, err := o.client.request(ctx, "GET", path, nil)
+	body, resp, err := o.request(ctx, "GetProject", "GET", path, nil)
 	if err != nil {
 		return Project{}, resp, errors.Wrap(err, "unable to get project")
 	}
@@ -197,10 +214,11 @@ func (o *Organization) GetProject(ctx context.Context, projectUUID string) (Proj
 func (o *Organization) CreateProject(ctx context.Context, p ProjectCreateRequest) (Project, Response, error) {
 	path := fmt.Sprintf("/organizations/%s/projects", o.UUID)
 
-	body, resp, err := o.client.request(ctx, "POST", path, p)
+	body, resp, err := o.request(ctx, "CreateProject", "POST", path, p)
 	if err != nil {
 		return Project{}, resp, errors.Wrap(err, "unable to create project")
 	}
+	o.invalidateProjects()
 
 	var project projectResponse
 	if err = json.Unmarshal(body, &project); err != nil {
@@ -216,7 +234,7 @@ func (o *Organization) CreateProject(ctx context.Context, p ProjectCreateRequest
 func (o *Organization) UpdateProject(ctx context.Context, projectUUID string, p ProjectUpdateRequest) (Project, Response, error) {
 	path := fmt.Sprintf("/organizations/%s/projects/%s", o.UUID, projectUUID)
 
-	body, resp, err := o.client.request(ctx, "PUT", path, p)
+	body, resp, err := o.request(ctx, "UpdateProject", "PUT", path, p)
 	if err != nil {
 		return Project{}, resp, errors.Wrap(err, "unable to update project")
 	}
@@ -235,7 +253,7 @@ func (o *Organization) UpdateProject(ctx context.Context, projectUUID string, p
 func (o *Organization) ResetProjectAESKey(ctx context.Context, projectUUID string) (Project, Response, error) {
 	path := fmt.Sprintf("/organizations/%s/projects/%s/reset_aes_key", o.UUID, projectUUID)
 
-	_, resp, err := o.client.request(ctx, "POST", path, nil)
+	_, resp, err := o.request(ctx, "ResetProjectAESKey", "POST", path, nil)
 	if err != nil {
 		return Project{}, resp, errors.Wrap(err, "unable to reset project AES key")
 	}
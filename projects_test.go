@@ -112,14 +112,14 @@ func TestListProjects(t *testing.T) {
 					{
 						Notifier:      "github",
 						BranchMatch:   "exact",
-						BuildStatuses: []string{"failed", "started", "recovered", "success"},
+						BuildStatuses: []codeship.NotifyOn{"failed", "started", "recovered", "success"},
 						Target:        "all",
 					},
 					{
 						Notifier:      "email",
 						BranchMatch:   "exact",
 						Options:       codeship.NotificationOptions{},
-						BuildStatuses: []string{"failed", "recovered"},
+						BuildStatuses: []codeship.NotifyOn{"failed", "recovered"},
 						Target:        "all",
 					},
 				},
@@ -156,6 +156,65 @@ func TestListProjects(t *testing.T) {
 	}
 }
 
+func TestListAllProjects(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    []string
+		err     string
+	}{
+		{
+			name: "multiple pages",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "50", r.URL.Query().Get("per_page"))
+
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Query().Get("page") {
+				case "1":
+					w.Header().Set("Link", `<https://api.codeship.com/v2/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects?page=2>; rel="next", <https://api.codeship.com/v2/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects?page=2>; rel="last"`)
+					fmt.Fprint(w, `{"projects": [{"uuid": "project-1"}, {"uuid": "project-2"}]}`)
+				case "2":
+					fmt.Fprint(w, `{"projects": [{"uuid": "project-3"}]}`)
+				default:
+					t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+				}
+			},
+			want: []string{"project-1", "project-2", "project-3"},
+		},
+		{
+			name: "error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, fixture("not_found.json"), "organization")
+			},
+			err: "unable to list projects: organization not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardown := setup()
+			defer teardown()
+
+			mux.HandleFunc("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects", tt.handler)
+
+			projects, err := org.ListAllProjects(context.Background())
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			var got []string
+			for _, p := range projects {
+				got = append(got, p.UUID)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestGetProject(t *testing.T) {
 	type args struct {
 		organizationUUID string
@@ -249,7 +308,7 @@ func TestGetProject(t *testing.T) {
 					{
 						Notifier:      "github",
 						BranchMatch:   "exact",
-						BuildStatuses: []string{"failed", "started", "recovered", "success"},
+						BuildStatuses: []codeship.NotifyOn{"failed", "started", "recovered", "success"},
 						Target:        "all",
 						Options: codeship.NotificationOptions{
 							Key:  "foo",
@@ -261,7 +320,7 @@ func TestGetProject(t *testing.T) {
 						Notifier:      "email",
 						BranchMatch:   "exact",
 						Options:       codeship.NotificationOptions{},
-						BuildStatuses: []string{"failed", "recovered"},
+						BuildStatuses: []codeship.NotifyOn{"failed", "recovered"},
 						Target:        "all",
 					},
 				},
@@ -0,0 +1,142 @@
+package codeship
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	// defaultRedactedHeaders are the HTTP headers redacted from verbose logs by default
+	defaultRedactedHeaders = []string{
+		"Authorization",
+		"Cookie",
+		"Proxy-Authorization",
+		"Set-Cookie",
+	}
+
+	// defaultRedactedFields are the JSON fields redacted from verbose logs by default
+	defaultRedactedFields = []string{
+		"access_token",
+		"aes_key",
+		"ssh_key",
+		"environment_variables.value",
+		"options.key",
+	}
+)
+
+// dumpRequest returns the request as a string suitable for verbose logging
+// with sensitive headers and JSON fields redacted
+func (c *Client) dumpRequest(req *http.Request) string {
+	r := req.Clone(req.Context())
+	r.Header = c.redactHeader(req.Header)
+
+	dump, _ := httputil.DumpRequest(r, false)
+
+	var body []byte
+	if req.GetBody != nil {
+		if rc, err := req.GetBody(); err == nil {
+			body, _ = ioutil.ReadAll(rc)
+			_ = rc.Close()
+		}
+	}
+
+	return string(dump) + string(c.redactBody(body))
+}
+
+// dumpResponse returns the response as a string suitable for verbose logging
+// with sensitive headers and JSON fields redacted
+func (c *Client) dumpResponse(resp *http.Response, body []byte) string {
+	r := *resp
+	r.Header = c.redactHeader(resp.Header)
+
+	dump, _ := httputil.DumpResponse(&r, false)
+
+	return string(dump) + string(c.redactBody(body))
+}
+
+func (c *Client) redactHeader(header http.Header) http.Header {
+	h := cloneHeader(header)
+	for _, name := range c.redactedHeaders {
+		if _, ok := h[http.CanonicalHeaderKey(name)]; ok {
+			h.Set(name, redacted)
+		}
+	}
+	return h
+}
+
+// redactBody replaces the values of redacted fields in a JSON body. Bodies
+// that are not valid JSON are returned unchanged.
+func (c *Client) redactBody(body []byte) []byte {
+	if len(body) == 0 || len(c.redactedFields) == 0 {
+		return body
+	}
+
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return body
+	}
+
+	fields := make([][]string, 0, len(c.redactedFields))
+	for _, f := range c.redactedFields {
+		fields = append(fields, strings.Split(f, "."))
+	}
+
+	out, err := json.MarshalIndent(redactValue(v, nil, fields), "", "  ")
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// redactValue walks a decoded JSON value, replacing any value whose key path
+// ends with one of fields. Arrays do not contribute to the key path.
+func redactValue(v interface{}, path []string, fields [][]string) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			childPath := append(path[:len(path):len(path)], k)
+			if matchesField(childPath, fields) {
+				if child != nil {
+					t[k] = redacted
+				}
+				continue
+			}
+			t[k] = redactValue(child, childPath, fields)
+		}
+	case []interface{}:
+		for i, child := range t {
+			t[i] = redactValue(child, path, fields)
+		}
+	}
+	return v
+}
+
+func matchesField(path []string, fields [][]string) bool {
+	for _, f := range fields {
+		if len(f) > len(path) {
+			continue
+		}
+
+		suffix := path[len(path)-len(f):]
+		match := true
+		for i := range f {
+			if suffix[i] != f[i] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
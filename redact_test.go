@@ -0,0 +1,131 @@
+package codeship_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerboseRedaction(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []codeship.Option
+		contains   []string
+		notContain []string
+	}{
+		{
+			name: "redacts sensitive values by default",
+			contains: []string{
+				"Authorization: [REDACTED]",
+				`"access_token": "[REDACTED]"`,
+				`"aes_key": "[REDACTED]"`,
+				`"ssh_key": "[REDACTED]"`,
+				`"value": "[REDACTED]"`,
+				`"name": "SECRET"`,
+				`"key": "[REDACTED]"`,
+				`"room": "devs"`,
+			},
+			notContain: []string{
+				"Basic dXNlcm5hbWU6cGFzc3dvcmQ=",
+				"Bearer token",
+				`"token"`,
+				"aeskey",
+				"ssh-rsa key",
+				"hunter2",
+				`"foo"`,
+			},
+		},
+		{
+			name: "custom redaction lists",
+			opts: []codeship.Option{
+				codeship.RedactHeaders("X-Custom"),
+				codeship.RedactFields("room"),
+			},
+			contains: []string{
+				"X-Custom: [REDACTED]",
+				"Bearer token",
+				`"aes_key": "aeskey"`,
+				`"value": "hunter2"`,
+				`"room": "[REDACTED]"`,
+			},
+			notContain: []string{
+				"shh",
+				`"devs"`,
+			},
+		},
+		{
+			name: "redaction disabled",
+			opts: []codeship.Option{
+				codeship.RedactHeaders(),
+				codeship.RedactFields(),
+			},
+			contains: []string{
+				"Bearer token",
+				"ssh-rsa key",
+				"hunter2",
+			},
+			notContain: []string{
+				"[REDACTED]",
+			},
+		},
+	}
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprint(w, fixture("auth/success.json"))
+			})
+			mux.HandleFunc("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/0059df30-7701-0135-8810-6e5f001a2e3c", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprint(w, fixture("projects/get.json"))
+			})
+
+			var buf bytes.Buffer
+			opts := append([]codeship.Option{
+				codeship.BaseURL(server.URL),
+				codeship.Verbose(true),
+				codeship.Logger(log.New(&buf, "", 0)),
+				codeship.Headers(http.Header{"X-Custom": []string{"shh"}}),
+			}, tt.opts...)
+
+			c, err := codeship.New(codeship.NewBasicAuth("username", "password"), opts...)
+			require.NoError(err)
+
+			o, err := c.Organization(context.Background(), "codeship")
+			require.NoError(err)
+
+			_, _, err = o.UpdateProject(context.Background(), "0059df30-7701-0135-8810-6e5f001a2e3c", codeship.ProjectUpdateRequest{
+				EnvironmentVariables: []codeship.EnvironmentVariable{
+					{Name: "SECRET", Value: "hunter2"},
+				},
+			})
+			require.NoError(err)
+
+			out := buf.String()
+			for _, s := range tt.contains {
+				assert.Contains(out, s)
+			}
+			for _, s := range tt.notContain {
+				assert.NotContains(out, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package codeship
+
+import (
+	"context"
+	"fmt"
+)
+
+// Scopes granted to an Organization when authenticating
+const (
+	ScopeProjectRead  = "project.read"
+	ScopeProjectWrite = "project.write"
+	ScopeBuildRead    = "build.read"
+	ScopeBuildWrite   = "build.write"
+)
+
+// _operationScopes is the scope required by each Organization method, keyed
+// by operation name
+var _operationScopes = map[string]string{
+	"ListProjects":       ScopeProjectRead,
+	"GetProject":         ScopeProjectRead,
+	"CreateProject":      ScopeProjectWrite,
+	"UpdateProject":      ScopeProjectWrite,
+	"ResetProjectAESKey": ScopeProjectWrite,
+	"ListBuilds":         ScopeBuildRead,
+	"GetBuild":           ScopeBuildRead,
+	"ListBuildPipelines": ScopeBuildRead,
+	"ListBuildServices":  ScopeBuildRead,
+	"ListBuildSteps":     ScopeBuildRead,
+	"CreateBuild":        ScopeBuildWrite,
+	"StopBuild":          ScopeBuildWrite,
+	"RestartBuild":       ScopeBuildWrite,
+}
+
+// RequiredScope returns the scope an Organization needs to call the method
+// with the given name, e.g. build.write for StopBuild, or an empty string if
+// the method is unknown
+func RequiredScope(method string) string {
+	return _operationScopes[method]
+}
+
+// ErrInsufficientScope occurs when an Organization calls a method it does not
+// have the scope for. The request is not sent.
+type ErrInsufficientScope struct {
+	Organization string
+	Operation    string
+	Scope        string
+}
+
+func (e ErrInsufficientScope) Error() string {
+	return fmt.Sprintf("insufficient scope: %s requires scope %q, which organization %q does not have", e.Operation, e.Scope, e.Organization)
+}
+
+// HasScope reports whether the organization was granted scope
+func (o *Organization) HasScope(scope string) bool {
+	for _, s := range o.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// request checks that the organization has the scope required by op before
+// sending the request. Organizations without any scopes, for which the
+// scopes are unknown, are not checked.
+func (o *Organization) request(ctx context.Context, op, method, path string, params interface{}) ([]byte, Response, error) {
+	if !o.client.skipScopeCheck && len(o.Scopes) > 0 {
+		if scope := RequiredScope(op); scope != "" && !o.HasScope(scope) {
+			return nil, Response{}, ErrInsufficientScope{Organization: o.Name, Operation: op, Scope: scope}
+		}
+	}
+	return o.client.request(ctx, op, method, path, params)
+}
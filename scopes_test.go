@@ -0,0 +1,115 @@
+package codeship_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredScope(t *testing.T) {
+	tests := []struct {
+		method string
+		scope  string
+	}{
+		{method: "ListProjects", scope: codeship.ScopeProjectRead},
+		{method: "GetProject", scope: codeship.ScopeProjectRead},
+		{method: "CreateProject", scope: codeship.ScopeProjectWrite},
+		{method: "UpdateProject", scope: codeship.ScopeProjectWrite},
+		{method: "ResetProjectAESKey", scope: codeship.ScopeProjectWrite},
+		{method: "ListBuilds", scope: codeship.ScopeBuildRead},
+		{method: "GetBuild", scope: codeship.ScopeBuildRead},
+		{method: "ListBuildPipelines", scope: codeship.ScopeBuildRead},
+		{method: "ListBuildServices", scope: codeship.ScopeBuildRead},
+		{method: "ListBuildSteps", scope: codeship.ScopeBuildRead},
+		{method: "CreateBuild", scope: codeship.ScopeBuildWrite},
+		{method: "StopBuild", scope: codeship.ScopeBuildWrite},
+		{method: "RestartBuild", scope: codeship.ScopeBuildWrite},
+		{method: "Unknown", scope: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			assert.Equal(t, tt.scope, codeship.RequiredScope(tt.method))
+		})
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	teardown := setup()
+	defer teardown()
+
+	assert.True(t, org.HasScope(codeship.ScopeBuildWrite))
+	assert.False(t, org.HasScope("admin"))
+}
+
+func TestScopeCheck(t *testing.T) {
+	projectUUID := "0059df30-7701-0135-8810-6e5f001a2e3c"
+	buildUUID := "25a3dd8c-eb3e-4e75-1298-8cbcbe621342"
+
+	tests := []struct {
+		name    string
+		opts    []codeship.Option
+		org     string
+		sent    bool
+		err     string
+		isScope bool
+	}{
+		{
+			name: "allowed",
+			org:  "codeship",
+			sent: true,
+		},
+		{
+			name:    "insufficient scope",
+			org:     "templates",
+			err:     `unable to stop build: insufficient scope: StopBuild requires scope "build.write", which organization "templates" does not have`,
+			isScope: true,
+		},
+		{
+			name: "check disabled",
+			opts: []codeship.Option{codeship.ScopeCheck(false)},
+			org:  "templates",
+			sent: true,
+			err:  "unable to stop build: rate limit exceeded",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux, client, teardown := setupOrganizations(t, tt.opts...)
+			defer teardown()
+
+			org, err := client.Organization(context.Background(), tt.org)
+			require.NoError(t, err)
+
+			var sent bool
+			mux.HandleFunc(fmt.Sprintf("/organizations/%s/projects/%s/builds/%s/stop", org.UUID, projectUUID, buildUUID), func(w http.ResponseWriter, r *http.Request) {
+				sent = true
+				if org.Name == "templates" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				w.WriteHeader(http.StatusAccepted)
+			})
+
+			ok, _, err := org.StopBuild(context.Background(), projectUUID, buildUUID)
+			assert.Equal(t, tt.sent, sent)
+			if tt.err == "" {
+				require.NoError(t, err)
+				assert.True(t, ok)
+				return
+			}
+
+			assert.EqualError(t, err, tt.err)
+			scopeErr, isScope := errors.Cause(err).(codeship.ErrInsufficientScope)
+			assert.Equal(t, tt.isScope, isScope)
+			if isScope {
+				assert.Equal(t, codeship.ErrInsufficientScope{Organization: "templates", Operation: "StopBuild", Scope: codeship.ScopeBuildWrite}, scopeErr)
+			}
+		})
+	}
+}
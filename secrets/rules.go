@@ -0,0 +1,113 @@
+package secrets
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Rule detects a kind of secret with a pattern. If the pattern has a
+// subexpression, the first one is the secret and the rest of the match is
+// context, e.g. the name of a command line flag.
+type Rule struct {
+	// ID identifies the rule in findings, e.g. github-token
+	ID          string
+	Description string
+	Pattern     *regexp.Regexp
+}
+
+// Rules are the built-in rules for well known tokens and credentials
+var Rules = []Rule{
+	{
+		ID:          "aws-access-key-id",
+		Description: "AWS access key ID",
+		Pattern:     regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`),
+	},
+	{
+		ID:          "github-token",
+		Description: "GitHub token",
+		Pattern:     regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`),
+	},
+	{
+		ID:          "gitlab-token",
+		Description: "GitLab personal access token",
+		Pattern:     regexp.MustCompile(`\b(glpat-[A-Za-z0-9_-]{20,})`),
+	},
+	{
+		ID:          "slack-token",
+		Description: "Slack token",
+		Pattern:     regexp.MustCompile(`\b(xox[abposr]-[A-Za-z0-9-]{10,})`),
+	},
+	{
+		ID:          "slack-webhook",
+		Description: "Slack incoming webhook URL",
+		Pattern:     regexp.MustCompile(`(https://hooks\.slack\.com/services/[A-Za-z0-9/]+)`),
+	},
+	{
+		ID:          "google-api-key",
+		Description: "Google API key",
+		Pattern:     regexp.MustCompile(`\b(AIza[0-9A-Za-z_-]{35})`),
+	},
+	{
+		ID:          "stripe-secret-key",
+		Description: "Stripe secret key",
+		Pattern:     regexp.MustCompile(`\b((?:sk|rk)_live_[0-9A-Za-z]{24,})`),
+	},
+	{
+		ID:          "private-key",
+		Description: "Private key",
+		Pattern:     regexp.MustCompile(`(-----BEGIN (?:[A-Z]+ )*PRIVATE KEY-----)`),
+	},
+	{
+		ID:          "url-credentials",
+		Description: "Password in a URL",
+		Pattern:     regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.-]*://[^/\s:@]+:([^/\s@$]+)@`),
+	},
+	{
+		ID:          "inline-credential",
+		Description: "Credential assigned inline, e.g. --password=secret",
+		Pattern:     regexp.MustCompile(`(?i)(?:password|passwd|secret|token|api[_-]?key)["']?\s*[=:]\s*["']?([^\s"'$]{8,})`),
+	},
+}
+
+// entropy returns the Shannon entropy of s in bits per character
+func entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// mask keeps the first 4 characters of secrets of at least 16 characters, so
+// that at most a quarter of a secret is shown and well known prefixes such as
+// ghp_ can be recognized, and hides shorter secrets entirely. The end and the
+// length of the secret are never revealed.
+func mask(s string) string {
+	const stars = "********"
+	if len(s) < 16 {
+		return stars
+	}
+	return s[:4] + stars
+}
+
+// looksRandom reports whether a value could be a generated secret. Values
+// with whitespace or a URL scheme are text or addresses rather than secrets.
+func looksRandom(s string, minLength int, minEntropy float64) bool {
+	if len(s) < minLength || strings.ContainsAny(s, " \t\n") || strings.Contains(s, "://") {
+		return false
+	}
+	return entropy(s) >= minEntropy
+}
@@ -0,0 +1,190 @@
+// Package secrets scans project settings for secrets stored in plain text.
+// Basic projects store environment variable values in the project, where
+// they are returned by the API, and secrets are often written inline in setup
+// and test commands. Findings only include masked previews of the secrets.
+package secrets
+
+import (
+	"context"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/pkg/errors"
+)
+
+// Confidence is how likely a finding is to be a secret
+type Confidence int
+
+const (
+	// ConfidenceHigh is a value matching a rule for a known kind of secret
+	ConfidenceHigh Confidence = iota
+	// ConfidenceMedium is a value that looks randomly generated
+	ConfidenceMedium
+)
+
+var _confidenceValueToName = map[Confidence]string{
+	ConfidenceHigh:   "high",
+	ConfidenceMedium: "medium",
+}
+
+func (c Confidence) String() string {
+	return _confidenceValueToName[c]
+}
+
+// MarshalText encodes the confidence as its name
+func (c Confidence) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// EntropyRule is the rule ID of findings for values that look randomly
+// generated
+const EntropyRule = "high-entropy"
+
+// Finding is a possible secret in the settings of a project
+type Finding struct {
+	ProjectUUID string `json:"project_uuid"`
+	ProjectName string `json:"project_name"`
+	// Field is the JSON name of the project field, with the key of the
+	// element, e.g. environment_variables[AWS_ACCESS_KEY_ID] or
+	// test_pipelines[tests]
+	Field      string     `json:"field"`
+	Rule       string     `json:"rule"`
+	Confidence Confidence `json:"confidence"`
+	// Preview is the secret with all but its first characters masked
+	Preview string `json:"preview"`
+}
+
+// Scanner finds secrets in project settings. The zero value uses the
+// built-in Rules and default entropy thresholds.
+type Scanner struct {
+	// Rules replace the built-in Rules if set
+	Rules []Rule
+	// MinLength is the length from which environment variable values are
+	// checked for entropy. Defaults to 20.
+	MinLength int
+	// MinEntropy is the Shannon entropy, in bits per character, from which an
+	// environment variable value looks randomly generated. Defaults to 4.
+	MinEntropy float64
+}
+
+// ScanProject returns the possible secrets in the environment variables,
+// setup commands and test pipeline commands of a project. Environment
+// variable values are checked with the rules and for entropy, and commands
+// are only checked with the rules.
+func (s Scanner) ScanProject(p codeship.Project) []Finding {
+	var findings []Finding
+	add := func(field, rule string, confidence Confidence, secret string) {
+		findings = append(findings, Finding{
+			ProjectUUID: p.UUID,
+			ProjectName: p.Name,
+			Field:       field,
+			Rule:        rule,
+			Confidence:  confidence,
+			Preview:     mask(secret),
+		})
+	}
+
+	for _, env := range p.EnvironmentVariables {
+		field := "environment_variables[" + env.Name + "]"
+
+		matched := s.match(env.Value, func(rule, secret string) {
+			add(field, rule, ConfidenceHigh, secret)
+		})
+		if !matched && looksRandom(env.Value, s.minLength(), s.minEntropy()) {
+			add(field, EntropyRule, ConfidenceMedium, env.Value)
+		}
+	}
+
+	for _, cmd := range p.SetupCommands {
+		s.match(cmd, func(rule, secret string) {
+			add("setup_commands", rule, ConfidenceHigh, secret)
+		})
+	}
+
+	for _, tp := range p.TestPipelines {
+		for _, cmd := range tp.Commands {
+			s.match(cmd, func(rule, secret string) {
+				add("test_pipelines["+tp.Name+"]", rule, ConfidenceHigh, secret)
+			})
+		}
+	}
+
+	return findings
+}
+
+// match calls found with each secret in value, reporting whether there were
+// any. Overlapping matches of several rules are only reported for the first
+// rule.
+func (s Scanner) match(value string, found func(rule, secret string)) bool {
+	rules := s.Rules
+	if rules == nil {
+		rules = Rules
+	}
+
+	var spans [][2]int
+	overlaps := func(start, end int) bool {
+		for _, span := range spans {
+			if start < span[1] && span[0] < end {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, rule := range rules {
+		for _, m := range rule.Pattern.FindAllStringSubmatchIndex(value, -1) {
+			start, end := m[0], m[1]
+			if len(m) > 2 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			if overlaps(start, end) {
+				continue
+			}
+			spans = append(spans, [2]int{start, end})
+			found(rule.ID, value[start:end])
+		}
+	}
+	return len(spans) > 0
+}
+
+func (s Scanner) minLength() int {
+	if s.MinLength > 0 {
+		return s.MinLength
+	}
+	return 20
+}
+
+func (s Scanner) minEntropy() float64 {
+	if s.MinEntropy > 0 {
+		return s.MinEntropy
+	}
+	return 4
+}
+
+// Client is the subset of *codeship.Organization used to scan an organization
+type Client interface {
+	ListAllProjects(ctx context.Context) ([]codeship.Project, error)
+}
+
+var _ Client = &codeship.Organization{}
+
+// Scan lists every project of an organization, along with its settings, and
+// scans them. Findings with high confidence are returned first.
+func (s Scanner) Scan(ctx context.Context, client Client) ([]Finding, error) {
+	projects, err := client.ListAllProjects(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to scan projects")
+	}
+
+	var high, medium []Finding
+	for _, p := range projects {
+		for _, f := range s.ScanProject(p) {
+			if f.Confidence == ConfidenceHigh {
+				high = append(high, f)
+			} else {
+				medium = append(medium, f)
+			}
+		}
+	}
+
+	return append(high, medium...), nil
+}
@@ -0,0 +1,87 @@
+package codeship
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Tracer allows you to bring your own tracing implementation, such as an
+// adapter for OpenTelemetry, without the client depending on it directly
+type Tracer interface {
+	// Start starts a new span as a child of any span in ctx, returning a
+	// context containing the new span
+	Start(ctx context.Context, name string) (context.Context, Span)
+	// Inject propagates the span context in ctx to the outgoing request
+	// headers, e.g. as W3C traceparent and tracestate headers
+	Inject(ctx context.Context, header http.Header)
+}
+
+// Span is a single traced operation
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Span attribute keys set by the client
+const (
+	AttributeOrganizationUUID = "codeship.organization.uuid"
+	AttributeProjectUUID      = "codeship.project.uuid"
+	AttributeBuildUUID        = "codeship.build.uuid"
+	AttributePage             = "codeship.page"
+	AttributeHTTPMethod       = "http.method"
+	AttributeHTTPStatusCode   = "http.status_code"
+)
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttribute(string, interface{}) {}
+func (noopSpan) RecordError(error)                {}
+func (noopSpan) End()                             {}
+
+// startSpan starts a span named after the operation, with attributes for the
+// resources identified by the request path
+func (c *Client) startSpan(ctx context.Context, op, method, path string) (context.Context, Span) {
+	if c.tracer == nil {
+		return ctx, noopSpan{}
+	}
+
+	ctx, span := c.tracer.Start(ctx, "codeship."+op)
+	span.SetAttribute(AttributeHTTPMethod, method)
+
+	u, err := url.Parse(path)
+	if err != nil {
+		return ctx, span
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		switch segments[i] {
+		case "organizations":
+			span.SetAttribute(AttributeOrganizationUUID, segments[i+1])
+		case "projects":
+			span.SetAttribute(AttributeProjectUUID, segments[i+1])
+		case "builds":
+			span.SetAttribute(AttributeBuildUUID, segments[i+1])
+		}
+	}
+
+	if page, err := strconv.Atoi(u.Query().Get("page")); err == nil {
+		span.SetAttribute(AttributePage, page)
+	}
+
+	return ctx, span
+}
+
+func endSpan(span Span, resp Response, err error) {
+	if resp.Response != nil {
+		span.SetAttribute(AttributeHTTPStatusCode, resp.StatusCode)
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
@@ -0,0 +1,153 @@
+package codeship_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type spanKey struct{}
+
+// fakeTracer records the spans it starts and injects a fixed traceparent header
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*fakeSpan
+}
+
+type fakeSpan struct {
+	name       string
+	parent     string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, codeship.Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	span := &fakeSpan{name: name, attributes: make(map[string]interface{})}
+	if parent, ok := ctx.Value(spanKey{}).(*fakeSpan); ok {
+		span.parent = parent.name
+	}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (t *fakeTracer) Inject(ctx context.Context, header http.Header) {
+	if span, ok := ctx.Value(spanKey{}).(*fakeSpan); ok {
+		header.Set("traceparent", "00-"+span.name+"-01")
+	}
+}
+
+func (s *fakeSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+func (s *fakeSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *fakeSpan) End() {
+	s.ended = true
+}
+
+func TestTracing(t *testing.T) {
+	teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/28123f10-e33d-5533-b53f-111ef8d7b14f/builds/25a3dd8c-eb3e-4e75-1298-8cbcbe621342/steps", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "00-codeship.ListBuildSteps-01", r.Header.Get("traceparent"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, fixture("builds/steps.json"))
+	})
+	mux.HandleFunc("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/28123f10-e33d-5533-b53f-111ef8d7b14f", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, fixture("not_found.json"), "project")
+	})
+
+	tracer := &fakeTracer{}
+	c, err := codeship.New(codeship.NewBasicAuth("test", "pass"), codeship.BaseURL(server.URL), codeship.Tracing(tracer))
+	require.NoError(t, err)
+
+	o, err := c.Organization(context.Background(), "codeship")
+	require.NoError(t, err)
+
+	_, _, err = o.ListBuildSteps(context.Background(), "28123f10-e33d-5533-b53f-111ef8d7b14f", "25a3dd8c-eb3e-4e75-1298-8cbcbe621342", codeship.Page(2))
+	require.NoError(t, err)
+
+	_, _, err = o.GetProject(context.Background(), "28123f10-e33d-5533-b53f-111ef8d7b14f")
+	require.Error(t, err)
+
+	require.Len(t, tracer.spans, 3)
+
+	auth := tracer.spans[0]
+	assert.Equal(t, "codeship.Authenticate", auth.name)
+	assert.Equal(t, 200, auth.attributes[codeship.AttributeHTTPStatusCode])
+	assert.True(t, auth.ended)
+
+	steps := tracer.spans[1]
+	assert.Equal(t, "codeship.ListBuildSteps", steps.name)
+	assert.Equal(t, map[string]interface{}{
+		codeship.AttributeHTTPMethod:       "GET",
+		codeship.AttributeOrganizationUUID: "28123f10-e33d-5533-b53f-111ef8d7b14f",
+		codeship.AttributeProjectUUID:      "28123f10-e33d-5533-b53f-111ef8d7b14f",
+		codeship.AttributeBuildUUID:        "25a3dd8c-eb3e-4e75-1298-8cbcbe621342",
+		codeship.AttributePage:             2,
+		codeship.AttributeHTTPStatusCode:   200,
+	}, steps.attributes)
+	assert.NoError(t, steps.err)
+	assert.True(t, steps.ended)
+
+	project := tracer.spans[2]
+	assert.Equal(t, "codeship.GetProject", project.name)
+	assert.Equal(t, 404, project.attributes[codeship.AttributeHTTPStatusCode])
+	assert.NotContains(t, project.attributes, codeship.AttributeBuildUUID)
+	assert.EqualError(t, project.err, "project not found")
+	assert.True(t, project.ended)
+}
+
+func TestTracingAuthenticationChildSpan(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// An expired token forces re-authentication on every request
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"access_token": "token", "expires_at": 1, "organizations": [{"name": "codeship", "uuid": "28123f10-e33d-5533-b53f-111ef8d7b14f"}]}`)
+	})
+	mux.HandleFunc("/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, fixture("projects/list.json"))
+	})
+
+	tracer := &fakeTracer{}
+	c, err := codeship.New(codeship.NewBasicAuth("test", "pass"), codeship.BaseURL(server.URL), codeship.Tracing(tracer))
+	require.NoError(t, err)
+
+	o, err := c.Organization(context.Background(), "codeship")
+	require.NoError(t, err)
+
+	tracer.spans = nil
+
+	_, _, err = o.ListProjects(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, tracer.spans, 2)
+	assert.Equal(t, "codeship.ListProjects", tracer.spans[0].name)
+	assert.Equal(t, "codeship.Authenticate", tracer.spans[1].name)
+	assert.Equal(t, "codeship.ListProjects", tracer.spans[1].parent)
+}
@@ -0,0 +1,242 @@
+package codeship
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// BuildEventType represents the kind of change reported by WatchBuilds
+type BuildEventType int
+
+const (
+	// BuildQueued is emitted when a build is first seen in a queued status
+	BuildQueued BuildEventType = iota
+	// BuildStarted is emitted when a build starts testing
+	BuildStarted
+	// BuildFinished is emitted when a build reaches a terminal status
+	BuildFinished
+	// StepChanged is emitted when a step of a running Pro build changes status
+	StepChanged
+	// WatchError is emitted when polling the API fails. Watching continues
+	// on the next interval.
+	WatchError
+)
+
+var _buildEventTypeValueToName = map[BuildEventType]string{
+	BuildQueued:   "build_queued",
+	BuildStarted:  "build_started",
+	BuildFinished: "build_finished",
+	StepChanged:   "step_changed",
+	WatchError:    "watch_error",
+}
+
+func (t BuildEventType) String() string {
+	return _buildEventTypeValueToName[t]
+}
+
+// BuildEvent is a single change observed by WatchBuilds
+type BuildEvent struct {
+	Type BuildEventType
+	// Build is the state of the build when the event was observed
+	Build Build
+	// Step is only set for StepChanged events
+	Step BuildStep
+	// Err is only set for WatchError events
+	Err error
+}
+
+// WatchOptions configures WatchBuilds
+type WatchOptions struct {
+	// Interval between polls of ListBuilds. Defaults to 10 seconds.
+	Interval time.Duration
+	// PerPage is the number of builds fetched per poll. Defaults to the API default.
+	PerPage int
+	// Steps enables polling ListBuildSteps for running builds and emitting
+	// StepChanged events. Only applicable to Pro projects.
+	Steps bool
+	// IgnoreExisting suppresses events for builds already present on the
+	// first poll, only reporting changes that happen afterwards.
+	IgnoreExisting bool
+}
+
+const defaultWatchInterval = 10 * time.Second
+
+// WatchBuilds polls the first page of ListBuilds for a project and emits an
+// event on the returned channel whenever a build changes status. Events are
+// deduplicated by build UUID and status, and builds are forgotten once they
+// drop off the first page. The channel is closed once ctx is done.
+//
+// When the client is configured with a ResponseCache, polls are made as
+// conditional requests so unchanged results are served from the cache.
+func (o *Organization) WatchBuilds(ctx context.Context, projectUUID string, opts WatchOptions) (<-chan BuildEvent, error) {
+	if projectUUID == "" {
+		return nil, errors.New("no project UUID provided")
+	}
+
+	if opts.Interval <= 0 {
+		opts.Interval = defaultWatchInterval
+	}
+
+	w := &buildWatcher{
+		org:         o,
+		projectUUID: projectUUID,
+		opts:        opts,
+		events:      make(chan BuildEvent),
+		builds:      make(map[string]string),
+		steps:       make(map[string]map[string]string),
+	}
+
+	go w.run(ctx)
+
+	return w.events, nil
+}
+
+type buildWatcher struct {
+	org         *Organization
+	projectUUID string
+	opts        WatchOptions
+	events      chan BuildEvent
+	// builds maps build UUID to the last seen status
+	builds map[string]string
+	// steps maps build UUID to step UUID to the last seen status
+	steps map[string]map[string]string
+}
+
+func (w *buildWatcher) run(ctx context.Context) {
+	defer close(w.events)
+
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+
+	emit := !w.opts.IgnoreExisting
+	for {
+		if !w.poll(ctx, emit) {
+			return
+		}
+		emit = true
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll fetches the latest builds and emits events for any changes. It returns
+// false once the context is done.
+func (w *buildWatcher) poll(ctx context.Context, emit bool) bool {
+	var opts []PaginationOption
+	if w.opts.PerPage > 0 {
+		opts = append(opts, PerPage(w.opts.PerPage))
+	}
+
+	list, _, err := w.org.ListBuilds(ctx, w.projectUUID, opts...)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		return w.send(ctx, BuildEvent{Type: WatchError, Err: err})
+	}
+
+	// The API returns the newest builds first, replay them oldest first so
+	// events are delivered in the order they happened
+	latest := make(map[string]bool, len(list.Builds))
+	for i := len(list.Builds) - 1; i >= 0; i-- {
+		build := list.Builds[i]
+		latest[build.UUID] = true
+
+		if w.builds[build.UUID] != build.Status {
+			w.builds[build.UUID] = build.Status
+			if emit && !w.send(ctx, BuildEvent{Type: buildEventType(build), Build: build}) {
+				return false
+			}
+		}
+
+		if !w.opts.Steps {
+			continue
+		}
+
+		if _, tracked := w.steps[build.UUID]; !tracked && build.Finished() {
+			continue
+		}
+
+		if !w.pollSteps(ctx, build, emit) {
+			return false
+		}
+
+		if build.Finished() {
+			delete(w.steps, build.UUID)
+		}
+	}
+
+	// Only the first page is polled, so builds that dropped off it are never
+	// seen again and can be forgotten
+	for uuid := range w.builds {
+		if !latest[uuid] {
+			delete(w.builds, uuid)
+			delete(w.steps, uuid)
+		}
+	}
+
+	return true
+}
+
+func (w *buildWatcher) pollSteps(ctx context.Context, build Build, emit bool) bool {
+	steps, _, err := w.org.ListBuildSteps(ctx, w.projectUUID, build.UUID)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		return w.send(ctx, BuildEvent{Type: WatchError, Build: build, Err: err})
+	}
+
+	seen, ok := w.steps[build.UUID]
+	if !ok {
+		seen = make(map[string]string)
+		w.steps[build.UUID] = seen
+	}
+
+	for _, step := range flattenSteps(steps.Steps) {
+		if seen[step.UUID] == step.Status {
+			continue
+		}
+		seen[step.UUID] = step.Status
+		if emit && !w.send(ctx, BuildEvent{Type: StepChanged, Build: build, Step: step}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (w *buildWatcher) send(ctx context.Context, event BuildEvent) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case w.events <- event:
+		return true
+	}
+}
+
+func buildEventType(b Build) BuildEventType {
+	switch {
+	case b.Finished():
+		return BuildFinished
+	case b.Status == BuildStatusTesting:
+		return BuildStarted
+	}
+	return BuildQueued
+}
+
+// flattenSteps returns the steps along with all of their nested steps, parents first
+func flattenSteps(steps []BuildStep) []BuildStep {
+	var flat []BuildStep
+	for _, step := range steps {
+		flat = append(flat, step)
+		flat = append(flat, flattenSteps(step.Steps)...)
+	}
+	return flat
+}
@@ -0,0 +1,233 @@
+package codeship_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	watchProjectUUID = "28123f10-e33d-5533-b53f-111ef8d7b14f"
+	watchBuildsPath  = "/organizations/28123f10-e33d-5533-b53f-111ef8d7b14f/projects/28123f10-e33d-5533-b53f-111ef8d7b14f/builds"
+)
+
+// sequenceHandler serves each body in turn, repeating the last one once exhausted
+func sequenceHandler(bodies ...string) http.HandlerFunc {
+	var (
+		mu    sync.Mutex
+		calls int
+	)
+	return func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		body := bodies[calls]
+		if calls < len(bodies)-1 {
+			calls++
+		}
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, body)
+	}
+}
+
+func buildsBody(statuses ...string) string {
+	body := `{"builds": [`
+	for i, status := range statuses {
+		if i > 0 {
+			body += ","
+		}
+		body += fmt.Sprintf(`{"uuid": "build-%d", "status": %q}`, i+1, status)
+	}
+	return body + `]}`
+}
+
+func collectEvents(t *testing.T, events <-chan codeship.BuildEvent, n int) []codeship.BuildEvent {
+	var got []codeship.BuildEvent
+	timeout := time.After(2 * time.Second)
+	for len(got) < n {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return got
+			}
+			got = append(got, e)
+		case <-timeout:
+			t.Fatalf("timed out waiting for events, got %d of %d", len(got), n)
+		}
+	}
+	return got
+}
+
+func TestWatchBuilds(t *testing.T) {
+	type event struct {
+		typ    codeship.BuildEventType
+		uuid   string
+		status string
+	}
+	tests := []struct {
+		name    string
+		opts    codeship.WatchOptions
+		handler http.HandlerFunc
+		steps   http.HandlerFunc
+		want    []event
+	}{
+		{
+			name: "emits build status changes",
+			handler: sequenceHandler(
+				buildsBody("waiting"),
+				buildsBody("waiting"),
+				buildsBody("testing"),
+				buildsBody("success"),
+			),
+			want: []event{
+				{typ: codeship.BuildQueued, uuid: "build-1", status: "waiting"},
+				{typ: codeship.BuildStarted, uuid: "build-1", status: "testing"},
+				{typ: codeship.BuildFinished, uuid: "build-1", status: "success"},
+			},
+		},
+		{
+			name: "replays builds oldest first",
+			handler: sequenceHandler(
+				buildsBody("testing", "error"),
+			),
+			want: []event{
+				{typ: codeship.BuildFinished, uuid: "build-2", status: "error"},
+				{typ: codeship.BuildStarted, uuid: "build-1", status: "testing"},
+			},
+		},
+		{
+			name: "ignores existing builds",
+			opts: codeship.WatchOptions{IgnoreExisting: true},
+			handler: sequenceHandler(
+				`{"builds": [{"uuid": "build-1", "status": "success"}]}`,
+				`{"builds": [{"uuid": "build-2", "status": "initiated"}, {"uuid": "build-1", "status": "success"}]}`,
+			),
+			want: []event{
+				{typ: codeship.BuildQueued, uuid: "build-2", status: "initiated"},
+			},
+		},
+		{
+			name: "forgets builds that drop off the page",
+			opts: codeship.WatchOptions{PerPage: 1},
+			handler: sequenceHandler(
+				`{"builds": [{"uuid": "build-1", "status": "success"}]}`,
+				`{"builds": [{"uuid": "build-2", "status": "success"}]}`,
+				`{"builds": [{"uuid": "build-1", "status": "success"}]}`,
+			),
+			want: []event{
+				{typ: codeship.BuildFinished, uuid: "build-1", status: "success"},
+				{typ: codeship.BuildFinished, uuid: "build-2", status: "success"},
+				{typ: codeship.BuildFinished, uuid: "build-1", status: "success"},
+			},
+		},
+		{
+			name: "emits step changes",
+			opts: codeship.WatchOptions{Steps: true},
+			handler: sequenceHandler(
+				buildsBody("testing"),
+				buildsBody("testing"),
+				buildsBody("success"),
+			),
+			steps: sequenceHandler(
+				`{"steps": [{"uuid": "step-1", "status": "running", "steps": [{"uuid": "step-2", "status": "waiting"}]}]}`,
+				`{"steps": [{"uuid": "step-1", "status": "running", "steps": [{"uuid": "step-2", "status": "success"}]}]}`,
+				`{"steps": [{"uuid": "step-1", "status": "success", "steps": [{"uuid": "step-2", "status": "success"}]}]}`,
+			),
+			want: []event{
+				{typ: codeship.BuildStarted, uuid: "build-1", status: "testing"},
+				{typ: codeship.StepChanged, uuid: "step-1", status: "running"},
+				{typ: codeship.StepChanged, uuid: "step-2", status: "waiting"},
+				{typ: codeship.StepChanged, uuid: "step-2", status: "success"},
+				{typ: codeship.BuildFinished, uuid: "build-1", status: "success"},
+				{typ: codeship.StepChanged, uuid: "step-1", status: "success"},
+			},
+		},
+		{
+			name: "reports polling errors",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, fixture("not_found.json"), "project")
+			},
+			want: []event{
+				{typ: codeship.WatchError},
+			},
+		},
+	}
+
+	assert := assert.New(t)
+	require := require.New(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teardown := setup()
+			defer teardown()
+
+			mux.HandleFunc(watchBuildsPath, tt.handler)
+			if tt.steps != nil {
+				mux.HandleFunc(watchBuildsPath+"/build-1/steps", tt.steps)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			opts := tt.opts
+			opts.Interval = 5 * time.Millisecond
+
+			events, err := org.WatchBuilds(ctx, watchProjectUUID, opts)
+			require.NoError(err)
+
+			got := collectEvents(t, events, len(tt.want))
+			require.Len(got, len(tt.want))
+
+			for i, want := range tt.want {
+				assert.Equal(want.typ, got[i].Type, "event %d", i)
+
+				switch want.typ {
+				case codeship.StepChanged:
+					assert.Equal(want.uuid, got[i].Step.UUID, "event %d", i)
+					assert.Equal(want.status, got[i].Step.Status, "event %d", i)
+				case codeship.WatchError:
+					assert.EqualError(got[i].Err, "unable to list builds: project not found")
+				default:
+					assert.Equal(want.uuid, got[i].Build.UUID, "event %d", i)
+					assert.Equal(want.status, got[i].Build.Status, "event %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchBuildsClosesChannel(t *testing.T) {
+	teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc(watchBuildsPath, sequenceHandler(buildsBody("success")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, err := org.WatchBuilds(ctx, watchProjectUUID, codeship.WatchOptions{Interval: 5 * time.Millisecond})
+	require.NoError(t, err)
+
+	collectEvents(t, events, 1)
+	cancel()
+
+	for range events {
+	}
+}
+
+func TestWatchBuildsRequiresProject(t *testing.T) {
+	teardown := setup()
+	defer teardown()
+
+	_, err := org.WatchBuilds(context.Background(), "", codeship.WatchOptions{})
+	assert.EqualError(t, err, "no project UUID provided")
+}
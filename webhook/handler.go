@@ -0,0 +1,121 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/pkg/errors"
+)
+
+// BuildGetter is the subset of *codeship.Organization used to enrich events
+type BuildGetter interface {
+	GetBuild(ctx context.Context, projectUUID, buildUUID string) (codeship.Build, codeship.Response, error)
+}
+
+var _ BuildGetter = &codeship.Organization{}
+
+// Event is a build notification delivered to a HandlerFunc
+type Event struct {
+	Payload Payload
+	// Build is mapped from the payload, or fetched with GetBuild when the
+	// Dispatcher has a client and the payload includes UUIDs
+	Build codeship.Build
+	// Enriched is true if Build was fetched with GetBuild
+	Enriched bool
+}
+
+// HandlerFunc handles a build notification
+type HandlerFunc func(ctx context.Context, event Event) error
+
+type route struct {
+	statuses map[string]bool
+	fn       HandlerFunc
+}
+
+// Dispatcher routes build notifications to handlers by build status
+type Dispatcher struct {
+	client BuildGetter
+	routes []route
+}
+
+// NewDispatcher creates a Dispatcher. If client is not nil, builds are fetched
+// with GetBuild so that handlers receive the complete build, including fields
+// such as QueuedAt and Links which are not part of the notification.
+func NewDispatcher(client BuildGetter) *Dispatcher {
+	return &Dispatcher{client: client}
+}
+
+// On registers fn to be called for notifications with any of the given build
+// statuses, or for all notifications if no statuses are given. Handlers are
+// called in the order they were registered.
+func (d *Dispatcher) On(fn HandlerFunc, statuses ...string) {
+	r := route{fn: fn}
+	if len(statuses) > 0 {
+		r.statuses = make(map[string]bool, len(statuses))
+		for _, s := range statuses {
+			r.statuses[s] = true
+		}
+	}
+	d.routes = append(d.routes, r)
+}
+
+// Dispatch maps the payload to an Event and calls the matching handlers,
+// stopping at the first error
+func (d *Dispatcher) Dispatch(ctx context.Context, p Payload) error {
+	event := Event{
+		Payload: p,
+		Build:   p.ToBuild(),
+	}
+
+	if d.client != nil && p.Build.ProjectUUID != "" && p.Build.BuildUUID != "" {
+		build, _, err := d.client.GetBuild(ctx, p.Build.ProjectUUID, p.Build.BuildUUID)
+		if err != nil {
+			return errors.Wrap(err, "unable to enrich webhook build")
+		}
+		event.Build = build
+		event.Enriched = true
+	}
+
+	for _, r := range d.routes {
+		if r.statuses != nil && !r.statuses[p.Build.Status] {
+			continue
+		}
+		if err := r.fn(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// maxBodyBytes limits the size of webhook payloads read by the handler
+const maxBodyBytes = 1 << 20
+
+// NewHandler returns an http.Handler that parses and validates webhook
+// payloads and passes them to the Dispatcher. It responds with 405 for
+// non-POST requests, 400 for invalid payloads, 500 if a handler returns an
+// error and 204 otherwise.
+func NewHandler(d *Dispatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		p, err := Parse(io.LimitReader(r.Body, maxBodyBytes))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := d.Dispatch(r.Context(), p); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
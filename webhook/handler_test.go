@@ -0,0 +1,170 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type getter struct {
+	build codeship.Build
+	err   error
+	calls int
+}
+
+func (g *getter) GetBuild(ctx context.Context, projectUUID, buildUUID string) (codeship.Build, codeship.Response, error) {
+	g.calls++
+	return g.build, codeship.Response{}, g.err
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	p, err := webhook.Parse(strings.NewReader(fixture(t)))
+	require.NoError(t, err)
+
+	queuedAt := time.Date(2017, 9, 13, 17, 13, 35, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		getter   *getter
+		statuses []string
+		called   bool
+		enriched bool
+		err      string
+	}{
+		{
+			name:   "all statuses",
+			called: true,
+		},
+		{
+			name:     "matching status",
+			statuses: []string{codeship.BuildStatusError, codeship.BuildStatusSuccess},
+			called:   true,
+		},
+		{
+			name:     "other status",
+			statuses: []string{codeship.BuildStatusError},
+		},
+		{
+			name:     "enriched",
+			getter:   &getter{build: codeship.Build{UUID: p.Build.BuildUUID, QueuedAt: queuedAt}},
+			called:   true,
+			enriched: true,
+		},
+		{
+			name:   "enrich error",
+			getter: &getter{err: errors.New("boom")},
+			err:    "unable to enrich webhook build: boom",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d *webhook.Dispatcher
+			if tt.getter != nil {
+				d = webhook.NewDispatcher(tt.getter)
+			} else {
+				d = webhook.NewDispatcher(nil)
+			}
+
+			var got *webhook.Event
+			d.On(func(ctx context.Context, event webhook.Event) error {
+				got = &event
+				return nil
+			}, tt.statuses...)
+
+			err := d.Dispatch(context.Background(), p)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.err, err.Error())
+				assert.Nil(t, got)
+				return
+			}
+			require.NoError(t, err)
+
+			if !tt.called {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, p, got.Payload)
+			assert.Equal(t, tt.enriched, got.Enriched)
+			if tt.enriched {
+				assert.Equal(t, queuedAt, got.Build.QueuedAt)
+				assert.Equal(t, 1, tt.getter.calls)
+			} else {
+				assert.Equal(t, p.ToBuild(), got.Build)
+			}
+		})
+	}
+}
+
+func TestNewHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		handlerErr error
+		status     int
+		handled    bool
+	}{
+		{
+			name:    "success",
+			method:  http.MethodPost,
+			body:    "fixture",
+			status:  http.StatusNoContent,
+			handled: true,
+		},
+		{
+			name:   "method not allowed",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+		},
+		{
+			name:   "invalid payload",
+			method: http.MethodPost,
+			body:   `{"build": {}}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:       "handler error",
+			method:     http.MethodPost,
+			body:       "fixture",
+			handlerErr: errors.New("boom"),
+			status:     http.StatusInternalServerError,
+			handled:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.body
+			if body == "fixture" {
+				body = fixture(t)
+			}
+
+			var handled bool
+			d := webhook.NewDispatcher(nil)
+			d.On(func(ctx context.Context, event webhook.Event) error {
+				handled = true
+				return tt.handlerErr
+			})
+
+			req := httptest.NewRequest(tt.method, "/codeship", strings.NewReader(body))
+			rec := httptest.NewRecorder()
+			webhook.NewHandler(d).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.handled, handled)
+			if tt.status == http.StatusMethodNotAllowed {
+				assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Package webhook receives build notifications sent by Codeship webhook
+// notification rules and maps them to codeship.Build values.
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/pkg/errors"
+)
+
+// Payload is the body of a webhook build notification
+type Payload struct {
+	Build BuildPayload `json:"build"`
+}
+
+// BuildPayload describes the build a notification was sent for. The UUID
+// fields are only sent for projects on the v2 API.
+type BuildPayload struct {
+	BuildID          uint      `json:"build_id"`
+	BuildUUID        string    `json:"build_uuid,omitempty"`
+	BuildURL         string    `json:"build_url"`
+	Branch           string    `json:"branch"`
+	CommitID         string    `json:"commit_id"`
+	CommitURL        string    `json:"commit_url"`
+	Committer        string    `json:"committer"`
+	FinishedAt       time.Time `json:"finished_at,omitempty"`
+	Message          string    `json:"message"`
+	OrganizationUUID string    `json:"organization_uuid,omitempty"`
+	ProjectFullName  string    `json:"project_full_name"`
+	ProjectID        uint      `json:"project_id"`
+	ProjectName      string    `json:"project_name"`
+	ProjectUUID      string    `json:"project_uuid,omitempty"`
+	ShortCommitID    string    `json:"short_commit_id"`
+	StartedAt        time.Time `json:"started_at,omitempty"`
+	Status           string    `json:"status"`
+}
+
+// Parse decodes a webhook payload from r and validates it
+func Parse(r io.Reader) (Payload, error) {
+	var p Payload
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return Payload{}, errors.Wrap(err, "unable to decode webhook payload")
+	}
+
+	if err := p.Validate(); err != nil {
+		return Payload{}, err
+	}
+
+	return p, nil
+}
+
+// Validate checks that the payload identifies a build and has a known status
+func (p Payload) Validate() error {
+	b := p.Build
+
+	if b.BuildID == 0 && b.BuildUUID == "" {
+		return errors.New("invalid webhook payload: build.build_id or build.build_uuid is required")
+	}
+
+	if b.ProjectID == 0 && b.ProjectUUID == "" {
+		return errors.New("invalid webhook payload: build.project_id or build.project_uuid is required")
+	}
+
+	switch b.Status {
+	case codeship.BuildStatusInitiated,
+		codeship.BuildStatusWaiting,
+		codeship.BuildStatusTesting,
+		codeship.BuildStatusSuccess,
+		codeship.BuildStatusError,
+		codeship.BuildStatusStopped,
+		codeship.BuildStatusIgnored,
+		codeship.BuildStatusBlocked,
+		codeship.BuildStatusInfrastructureFailure,
+		codeship.BuildStatusSkippedCommit:
+	case "":
+		return errors.New("invalid webhook payload: build.status is required")
+	default:
+		return errors.Errorf("invalid webhook payload: unknown build.status %q", b.Status)
+	}
+
+	return nil
+}
+
+// ToBuild maps the payload to a codeship.Build. Fields that are not part of the
+// notification, such as QueuedAt and Links, are left empty.
+func (p Payload) ToBuild() codeship.Build {
+	b := p.Build
+	return codeship.Build{
+		AllocatedAt:      b.StartedAt,
+		Branch:           b.Branch,
+		CommitMessage:    b.Message,
+		CommitSha:        b.CommitID,
+		FinishedAt:       b.FinishedAt,
+		OrganizationUUID: b.OrganizationUUID,
+		ProjectID:        b.ProjectID,
+		ProjectUUID:      b.ProjectUUID,
+		Status:           b.Status,
+		Username:         b.Committer,
+		UUID:             b.BuildUUID,
+	}
+}
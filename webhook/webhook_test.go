@@ -0,0 +1,102 @@
+package webhook_test
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	codeship "github.com/codeship/codeship-go"
+	"github.com/codeship/codeship-go/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fixture(t *testing.T) string {
+	b, err := ioutil.ReadFile("testdata/build.json")
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestParse(t *testing.T) {
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name string
+		args args
+		err  string
+	}{
+		{
+			name: "success",
+			args: args{body: "fixture"},
+		},
+		{
+			name: "v1 payload without uuids",
+			args: args{body: `{"build": {"build_id": 973711, "project_id": 10213, "status": "testing"}}`},
+		},
+		{
+			name: "invalid json",
+			args: args{body: `{"build":`},
+			err:  "unable to decode webhook payload",
+		},
+		{
+			name: "missing build",
+			args: args{body: `{"build": {"project_id": 10213, "status": "testing"}}`},
+			err:  "invalid webhook payload: build.build_id or build.build_uuid is required",
+		},
+		{
+			name: "missing project",
+			args: args{body: `{"build": {"build_id": 973711, "status": "testing"}}`},
+			err:  "invalid webhook payload: build.project_id or build.project_uuid is required",
+		},
+		{
+			name: "missing status",
+			args: args{body: `{"build": {"build_id": 973711, "project_id": 10213}}`},
+			err:  "invalid webhook payload: build.status is required",
+		},
+		{
+			name: "unknown status",
+			args: args{body: `{"build": {"build_id": 973711, "project_id": 10213, "status": "sucess"}}`},
+			err:  `invalid webhook payload: unknown build.status "sucess"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := tt.args.body
+			if body == "fixture" {
+				body = fixture(t)
+			}
+
+			p, err := webhook.Parse(strings.NewReader(body))
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				assert.Equal(t, webhook.Payload{}, p)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, p.Build.Status)
+		})
+	}
+}
+
+func TestPayload_ToBuild(t *testing.T) {
+	p, err := webhook.Parse(strings.NewReader(fixture(t)))
+	require.NoError(t, err)
+
+	assert.Equal(t, codeship.Build{
+		AllocatedAt:      time.Date(2017, 9, 13, 17, 13, 40, 185000000, time.UTC),
+		Branch:           "master",
+		CommitMessage:    "Merge pull request #34 from codeship/feature/shallow-clone",
+		CommitSha:        "96943dc5269634c211b6fbb18896ecdcbd40a047",
+		FinishedAt:       time.Date(2017, 9, 13, 17, 15, 4, 628000000, time.UTC),
+		OrganizationUUID: "28123f10-e33d-5533-b53f-111ef8d7b14f",
+		ProjectID:        10213,
+		ProjectUUID:      "c38f3280-2d13-0134-d0b4-1e1b0ba06dd1",
+		Status:           codeship.BuildStatusSuccess,
+		Username:         "beanieboi",
+		UUID:             "25a3dd8c-eb3e-4e75-1298-8cbcbe621342",
+	}, p.ToBuild())
+}